@@ -2,11 +2,18 @@ package commands
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
-	"tempo/internal/storage"
+	"tempo/internal/assertion"
+	"tempo/internal/schedule"
+	"tempo/internal/service"
+	"tempo/internal/templating"
 	"tempo/internal/types"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -19,7 +26,25 @@ or use --interactive for a guided setup.
 
 Examples:
   tempo add health-check --url "https://api.example.com/health" --schedule "*/30 * * * * *"
-  tempo add --interactive`,
+  tempo add inventory-sync --url "https://api.example.com/sync" --schedule "0 0 * * * *" --tag warehouse
+  tempo add announcement --url "https://api.example.com/announce" --method POST --at "2026-11-01 09:00"
+  tempo add cache-warmup --url "https://api.example.com/warm" --every 90m
+  tempo add report --url "https://api.example.com/report" --schedule "0 */5 * * * *" --overlap skip
+  tempo add internal-sync --url "https://sync.internal/run" --every 1h --ca-cert ./internal-ca.pem --timeout 2m
+  tempo add billing --url "https://api.example.com/bill" --every 30s --alert-after 3 --alert-channel ops
+  tempo add --interactive
+
+Adding a job with the ID of an existing one fails unless --force is given.
+Use 'tempo update' or 'tempo edit' to change a job instead.
+
+--overlap decides what happens when a scheduled run is due while the previous
+one is still in progress: allow runs both, skip skips the new run, queue starts
+it when the previous run ends (up to --queue-limit waiting runs) and cancel
+cancels the previous run. Skipped and cancelled runs show up in 'tempo logs'.
+
+A running scheduler alerts once when a job fails --alert-after runs in a row,
+and again when it recovers. Without --alert-channel, --alert-webhook or
+--alert-slack the channels under "alerts" in ~/.tempo/config.json are used.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runAdd,
 }
@@ -28,18 +53,70 @@ var (
 	jobURL      string
 	jobMethod   string
 	jobSchedule string
+	jobTimeZone string
+	jobAt       string
+	jobEvery    time.Duration
+	jobAnchor   string
 	jobBody     string
 	jobHeaders  []string
+	jobTags     []string
+	jobOverlap  string
+	queueLimit  int
 	interactive bool
+	addForce    bool
+	addHTTP     httpFlags
+	addAlerts   alertFlags
+
+	retryAttempts   int
+	retryDelay      time.Duration
+	retryMultiplier float64
+	retryMaxDelay   time.Duration
+	retryJitter     float64
+	retryOn         []string
+
+	expectStatus       []string
+	expectMaxLatency   time.Duration
+	expectHeaders      []string
+	expectBodyContains []string
+	expectBodyMatches  []string
+	expectJSON         []string
+	expectNoJSON       []string
+	expectJSONSchema   string
 )
 
 func init() {
 	addCmd.Flags().StringVarP(&jobURL, "url", "u", "", "Webhook URL")
 	addCmd.Flags().StringVarP(&jobMethod, "method", "m", "GET", "HTTP method (GET, POST, PUT, DELETE)")
 	addCmd.Flags().StringVarP(&jobSchedule, "schedule", "s", "", "Cron schedule expression (e.g., '*/30 * * * * *')")
+	addCmd.Flags().StringVar(&jobTimeZone, "timezone", "", "IANA time zone the schedule is evaluated in, e.g. 'Europe/Berlin' (default: the scheduler's local time zone)")
+	addCmd.Flags().StringVar(&jobAt, "at", "", "Run once at a time like '2025-01-31 09:00' or after a duration like '2h', instead of --schedule")
+	addCmd.Flags().DurationVar(&jobEvery, "every", 0, "Run at a fixed interval like '90m', instead of --schedule")
+	addCmd.Flags().StringVar(&jobAnchor, "anchor", "", "Time the --every interval counts from (default: now)")
 	addCmd.Flags().StringVarP(&jobBody, "body", "b", "", "Request body")
-	addCmd.Flags().StringSliceVarP(&jobHeaders, "header", "H", []string{}, "HTTP headers (format: 'Key=Value')")
+	addCmd.Flags().StringArrayVarP(&jobHeaders, "header", "H", []string{}, "HTTP headers (format: 'Key=Value')")
+	addCmd.Flags().StringSliceVarP(&jobTags, "tag", "t", []string{}, "Tags for selecting the job in bulk, e.g. with 'tempo pause --tag'")
+	addCmd.Flags().StringVar(&jobOverlap, "overlap", "", "What to do when a run is due while the previous one is running: allow, skip, queue or cancel (default: allow)")
+	addCmd.Flags().IntVar(&queueLimit, "queue-limit", 0, "Runs that may wait for the previous one with --overlap queue (default: 1)")
+	addHTTP.register(addCmd)
+	addAlerts.register(addCmd)
 	addCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Interactive mode for guided setup")
+	addCmd.Flags().BoolVarP(&addForce, "force", "f", false, "Replace an existing job with the same ID")
+
+	addCmd.Flags().IntVar(&retryAttempts, "retries", 1, "Maximum attempts per run, including the first one")
+	addCmd.Flags().DurationVar(&retryDelay, "retry-delay", time.Second, "Delay before the first retry")
+	addCmd.Flags().Float64Var(&retryMultiplier, "retry-multiplier", 2, "Backoff multiplier applied to the delay after every retry")
+	addCmd.Flags().DurationVar(&retryMaxDelay, "retry-max-delay", time.Minute, "Maximum delay between retries")
+	addCmd.Flags().Float64Var(&retryJitter, "retry-jitter", 0.2, "Fraction of each delay that is randomized (0-1)")
+	addCmd.Flags().StringSliceVar(&retryOn, "retry-on", []string{}, "Retryable conditions: network, timeout, status codes or classes (default: network,timeout,429,5xx)")
+
+	addCmd.Flags().StringSliceVar(&expectStatus, "expect-status", []string{}, "Accepted status codes, classes or ranges, e.g. '200,3xx,400-404' (default: below 400)")
+	addCmd.Flags().DurationVar(&expectMaxLatency, "expect-max-latency", 0, "Maximum response time")
+	addCmd.Flags().StringArrayVar(&expectHeaders, "expect-header", []string{}, "Response header that must match a regular expression (format: 'Name=pattern')")
+	addCmd.Flags().StringArrayVar(&expectBodyContains, "expect-body-contains", []string{}, "Text the response body must contain")
+	addCmd.Flags().StringArrayVar(&expectBodyMatches, "expect-body-matches", []string{}, "Regular expression the response body must match")
+	addCmd.Flags().StringArrayVar(&expectJSON, "expect-json", []string{}, "JSONPath that must exist, or equal a JSON value (format: '$.path' or '$.path=value')")
+	addCmd.Flags().StringArrayVar(&expectNoJSON, "expect-no-json", []string{}, "JSONPath that must not exist in the response body")
+	addCmd.Flags().StringVar(&expectJSONSchema, "expect-json-schema", "", "JSON Schema the response body must match, as a file path or inline JSON")
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
@@ -56,8 +133,8 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	if jobURL == "" {
 		return fmt.Errorf("URL is required. Use --url or --interactive")
 	}
-	if jobSchedule == "" {
-		return fmt.Errorf("schedule is required. Use --schedule or --interactive")
+	if jobSchedule == "" && jobAt == "" && jobEvery == 0 {
+		return fmt.Errorf("schedule is required. Use --schedule, --at, --every or --interactive")
 	}
 	if jobID == "" {
 		return fmt.Errorf("job ID is required")
@@ -78,28 +155,61 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		URL:      jobURL,
 		Method:   jobMethod,
 		CronExpr: jobSchedule,
+		TimeZone: jobTimeZone,
 		Body:     jobBody,
 		Headers:  headers,
+		Tags:     jobTags,
+
+		Overlap:    jobOverlap,
+		QueueLimit: queueLimit,
+	}
+	if err := setScheduleFlags(&job, jobAt, jobEvery, jobAnchor); err != nil {
+		return err
+	}
+	settings, err := addHTTP.apply(cmd, nil)
+	if err != nil {
+		return err
+	}
+	job.HTTP = settings
+	job.Alert = addAlerts.apply(cmd, nil)
+
+	if retryAttempts > 1 {
+		job.Retry = &types.RetryPolicy{
+			MaxAttempts:  retryAttempts,
+			InitialDelay: types.Duration(retryDelay),
+			Multiplier:   retryMultiplier,
+			MaxDelay:     types.Duration(retryMaxDelay),
+			Jitter:       retryJitter,
+			RetryOn:      retryOn,
+		}
+		if err := job.Retry.Validate(); err != nil {
+			return err
+		}
 	}
 
-	// Add job to storage
-	store, err := storage.NewStorage("")
+	assert, err := assertionsFromFlags()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %v", err)
+		return err
 	}
+	job.Assert = assert
 
-	if err := store.AddJob(job); err != nil {
-		return fmt.Errorf("failed to add job: %v", err)
+	if err := validateJob(job); err != nil {
+		return err
+	}
+
+	if err := saveNewJob(job); err != nil {
+		return err
 	}
 
-	fmt.Printf("✓ Added job '%s': %s %s\n", jobID, jobMethod, jobURL)
-	if jobBody != "" {
-		fmt.Printf("  Body: %.50s...\n", jobBody)
+	printAddedJob(job)
+	if job.Retry != nil {
+		fmt.Printf("  Retry: %s\n", formatRetryPolicy(job.Retry))
 	}
-	if len(headers) > 0 {
-		fmt.Printf("  Headers: %v\n", headers)
+	if job.Assert != nil {
+		fmt.Printf("  Assert: %s\n", formatAssertions(job.Assert))
 	}
 
+	reloadScheduler()
 	return nil
 }
 
@@ -141,6 +251,11 @@ func runInteractiveAdd(jobID string) error {
 		return fmt.Errorf("schedule cannot be empty")
 	}
 
+	// Get Time Zone
+	fmt.Print("Time Zone [local]: ")
+	timeZone, _ := reader.ReadString('\n')
+	timeZone = strings.TrimSpace(timeZone)
+
 	// Get Body
 	fmt.Print("Request Body (optional): ")
 	body, _ := reader.ReadString('\n')
@@ -169,27 +284,259 @@ func runInteractiveAdd(jobID string) error {
 		URL:      url,
 		Method:   method,
 		CronExpr: schedule,
+		TimeZone: timeZone,
 		Body:     body,
 		Headers:  headers,
 	}
 
-	// Add job to storage
-	store, err := storage.NewStorage("")
+	if err := validateJob(job); err != nil {
+		return err
+	}
+
+	if err := saveNewJob(job); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	printAddedJob(job)
+
+	reloadScheduler()
+	return nil
+}
+
+// saveNewJob stores a job, refusing to replace an existing one without --force
+func saveNewJob(job types.Job) error {
+	store, err := openStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %v", err)
 	}
+	defer store.Close()
 
-	if err := store.AddJob(job); err != nil {
+	errExists := fmt.Errorf("job '%s' already exists. Use 'tempo update' or 'tempo edit' to change it, or --force to replace it", job.ID)
+	err = store.UpdateJob(job.ID, func(_ types.Job, exists bool) (types.Job, error) {
+		if exists && !addForce {
+			return types.Job{}, errExists
+		}
+		return job, nil
+	})
+	if errors.Is(err, errExists) {
+		return err
+	}
+	if err != nil {
 		return fmt.Errorf("failed to add job: %v", err)
 	}
+	return nil
+}
 
-	fmt.Printf("\n✓ Added job '%s': %s %s\n", jobID, method, url)
-	if body != "" {
-		fmt.Printf("  Body: %.50s...\n", body)
+// setScheduleFlags gives the job a one-shot or interval schedule from the
+// --at, --every and --anchor flags. Times without an offset are read in the
+// job's time zone.
+func setScheduleFlags(job *types.Job, at string, every time.Duration, anchor string) error {
+	loc, err := schedule.Location(job.TimeZone)
+	if err != nil {
+		return err
 	}
-	if len(headers) > 0 {
-		fmt.Printf("  Headers: %v\n", headers)
+	now := time.Now().Truncate(time.Second)
+
+	if at != "" {
+		t, err := parseFutureTime("--at", at, now, loc)
+		if err != nil {
+			return err
+		}
+		job.At = &t
 	}
 
+	if anchor != "" && every == 0 {
+		return fmt.Errorf("--anchor requires --every")
+	}
+	if every != 0 {
+		job.Every = types.Duration(every)
+		start := now
+		if anchor != "" {
+			if start, err = parseTime("--anchor", anchor, now, loc); err != nil {
+				return err
+			}
+		}
+		job.Anchor = &start
+	}
 	return nil
 }
+
+// validateJob checks a complete job, such as one read from a file or editor
+func validateJob(job types.Job) error {
+	if job.ID == "" {
+		return fmt.Errorf("job ID is required")
+	}
+	if job.URL == "" {
+		return fmt.Errorf("URL is required")
+	}
+	if _, err := schedule.Parse(job); err != nil {
+		return err
+	}
+	if err := job.ValidateOverlap(); err != nil {
+		return err
+	}
+	// Creating a client also checks the certificate files
+	if _, err := service.NewHTTPClient(job.HTTP); err != nil {
+		return fmt.Errorf("invalid HTTP settings: %v", err)
+	}
+	if job.Retry != nil {
+		if err := job.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid retry policy: %v", err)
+		}
+	}
+	if err := assertion.Validate(job.Assert); err != nil {
+		return fmt.Errorf("invalid assertions: %v", err)
+	}
+	if err := validateAlertPolicy(job.Alert); err != nil {
+		return fmt.Errorf("invalid alert policy: %v", err)
+	}
+	return templating.Validate(job, nil)
+}
+
+// printAddedJob echoes a saved job with sensitive values redacted
+func printAddedJob(job types.Job) {
+	job = outputRedactor().Job(job)
+
+	fmt.Printf("✓ Added job '%s': %s %s\n", job.ID, job.Method, job.URL)
+	fmt.Printf("  Schedule: %s\n", job.Schedule())
+	if job.Body != "" {
+		fmt.Printf("  Body: %.50s...\n", job.Body)
+	}
+	if len(job.Headers) > 0 {
+		fmt.Printf("  Headers: %v\n", job.Headers)
+	}
+	if len(job.Tags) > 0 {
+		fmt.Printf("  Tags: %s\n", strings.Join(job.Tags, ", "))
+	}
+	if job.Overlap != "" {
+		fmt.Printf("  Overlap: %s\n", formatOverlap(job))
+	}
+	if settings := formatHTTPConfig(job.HTTP); settings != "" {
+		fmt.Printf("  HTTP: %s\n", settings)
+	}
+	if policy := formatAlertPolicy(job.Alert); policy != "" {
+		fmt.Printf("  Alerts: %s\n", policy)
+	}
+}
+
+// formatOverlap describes the job's overlap policy
+func formatOverlap(job types.Job) string {
+	if job.OverlapPolicy() == types.OverlapQueue {
+		return fmt.Sprintf("queue (up to %d waiting)", job.MaxQueued())
+	}
+	return job.OverlapPolicy()
+}
+
+// assertionsFromFlags builds the job's response assertions from the --expect-* flags
+func assertionsFromFlags() (*types.Assertions, error) {
+	assert := &types.Assertions{
+		Status:       expectStatus,
+		MaxLatency:   types.Duration(expectMaxLatency),
+		BodyContains: expectBodyContains,
+		BodyMatches:  expectBodyMatches,
+	}
+
+	for _, header := range expectHeaders {
+		name, pattern, found := strings.Cut(header, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid header assertion: %s. Use 'Name=pattern'", header)
+		}
+		if assert.Headers == nil {
+			assert.Headers = make(map[string]string)
+		}
+		assert.Headers[name] = pattern
+	}
+
+	for _, check := range expectJSON {
+		path, value, hasValue := strings.Cut(check, "=")
+		jsonCheck := types.JSONAssertion{Path: path}
+		if hasValue {
+			// Values that are not valid JSON are compared as strings
+			if err := json.Unmarshal([]byte(value), &jsonCheck.Equals); err != nil {
+				jsonCheck.Equals = value
+			}
+		}
+		assert.JSON = append(assert.JSON, jsonCheck)
+	}
+	for _, path := range expectNoJSON {
+		exists := false
+		assert.JSON = append(assert.JSON, types.JSONAssertion{Path: path, Exists: &exists})
+	}
+
+	if expectJSONSchema != "" {
+		if strings.HasPrefix(strings.TrimSpace(expectJSONSchema), "{") {
+			if err := json.Unmarshal([]byte(expectJSONSchema), &assert.JSONSchema); err != nil {
+				return nil, fmt.Errorf("invalid inline JSON schema: %v", err)
+			}
+		} else {
+			// The scheduler may run from another directory
+			path, err := filepath.Abs(expectJSONSchema)
+			if err != nil {
+				return nil, err
+			}
+			assert.JSONSchema = path
+		}
+	}
+
+	if len(assert.Status) == 0 && assert.MaxLatency == 0 && len(assert.Headers) == 0 &&
+		len(assert.BodyContains) == 0 && len(assert.BodyMatches) == 0 && len(assert.JSON) == 0 && assert.JSONSchema == nil {
+		return nil, nil
+	}
+
+	if err := assertion.Validate(assert); err != nil {
+		return nil, err
+	}
+	return assert, nil
+}
+
+// formatAssertions summarizes a job's response assertions on a single line
+func formatAssertions(a *types.Assertions) string {
+	var parts []string
+	if len(a.Status) > 0 {
+		parts = append(parts, "status "+strings.Join(a.Status, ","))
+	}
+	if a.MaxLatency > 0 {
+		parts = append(parts, "latency <= "+a.MaxLatency.String())
+	}
+	for name, pattern := range a.Headers {
+		parts = append(parts, fmt.Sprintf("header %s ~ %q", name, pattern))
+	}
+	for _, substring := range a.BodyContains {
+		parts = append(parts, fmt.Sprintf("body contains %q", substring))
+	}
+	for _, pattern := range a.BodyMatches {
+		parts = append(parts, fmt.Sprintf("body ~ %q", pattern))
+	}
+	for _, check := range a.JSON {
+		switch {
+		case check.Exists != nil && !*check.Exists:
+			parts = append(parts, check.Path+" absent")
+		case check.Equals != nil:
+			value, _ := json.Marshal(check.Equals)
+			parts = append(parts, fmt.Sprintf("%s == %s", check.Path, value))
+		default:
+			parts = append(parts, check.Path+" exists")
+		}
+	}
+	if a.JSONSchema != nil {
+		if path, ok := a.JSONSchema.(string); ok {
+			parts = append(parts, "schema "+path)
+		} else {
+			parts = append(parts, "inline schema")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatRetryPolicy summarizes a retry policy on a single line
+func formatRetryPolicy(policy *types.RetryPolicy) string {
+	return fmt.Sprintf("%d attempts, delay %s x%g (max %s, jitter %g), on %s",
+		policy.MaxAttempts,
+		policy.InitialDelay,
+		policy.Multiplier,
+		policy.MaxDelay,
+		policy.Jitter,
+		strings.Join(policy.Conditions(), ","),
+	)
+}
@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"tempo/internal/alert"
+	"tempo/internal/config"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var alertCmd = &cobra.Command{
+	Use:   "alert",
+	Short: "Test alert channels",
+	Long: `A running scheduler alerts when a job fails a number of runs in a row, and
+again when it recovers. Channels and rules that apply to every job are set under
+"alerts" in ~/.tempo/config.json; 'tempo add' and 'tempo update' change them
+per job with the --alert-* flags.
+
+Examples:
+  tempo alert test
+  tempo alert test health-check`,
+}
+
+var alertTestCmd = &cobra.Command{
+	Use:   "test [job-id]",
+	Short: "Send a test alert",
+	Long: `Send a test alert to the channels of a job, or to every configured channel
+without a job ID, to check that they are reachable.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAlertTest,
+}
+
+func init() {
+	alertCmd.AddCommand(alertTestCmd)
+}
+
+func runAlertTest(cmd *cobra.Command, args []string) error {
+	global, err := loadAlertDefaults()
+	if err != nil {
+		return err
+	}
+
+	test := types.Alert{Kind: types.AlertTest, JobID: "(none)", Time: time.Now()}
+	var policy types.AlertPolicy
+	if global != nil {
+		policy = *global
+	}
+	if len(args) > 0 {
+		store, err := openStorage()
+		if err != nil {
+			return fmt.Errorf("failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		job, exists := store.GetJob(args[0])
+		if !exists {
+			return fmt.Errorf("job '%s' not found", args[0])
+		}
+		if policy, err = job.Alert.WithDefaults(global); err != nil {
+			return err
+		}
+		if policy.Disabled {
+			return fmt.Errorf("alerts are disabled for job '%s'", job.ID)
+		}
+		test.JobID = job.ID
+		test.URL = outputRedactor().URL(job.URL)
+	}
+
+	if len(policy.Channels) == 0 {
+		return fmt.Errorf("no alert channels configured. Add them under \"alerts\" in %s or with --alert-webhook, --alert-slack or --alert-channel", config.FileName)
+	}
+
+	failed := 0
+	secrets := &secretResolver{prompt: true}
+	for _, channel := range policy.Channels {
+		resolved, used, err := alert.Resolve(channel, secrets)
+		if err == nil {
+			err = alert.Send(resolved, test)
+		}
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", channel, outputRedactor().WithValues(used...).Error(err))
+			failed++
+			continue
+		}
+		fmt.Printf("✓ Sent test alert to %s\n", channel)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d alert channel(s) failed", failed, len(policy.Channels))
+	}
+	return nil
+}
+
+// alertFlags are the alert settings accepted by add and update
+type alertFlags struct {
+	channels []string
+	webhooks []string
+	slack    []string
+	after    int
+	recovery bool
+	repeat   time.Duration
+	disabled bool
+}
+
+var alertChannelFlags = []string{"alert-channel", "alert-webhook", "alert-slack"}
+
+var alertFlagNames = append([]string{"alert-after", "alert-recovery", "alert-repeat", "no-alerts"}, alertChannelFlags...)
+
+func (f *alertFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.channels, "alert-channel", []string{}, "Send the job's alerts to a channel configured under \"alerts\" in config.json, instead of all of them")
+	cmd.Flags().StringArrayVar(&f.webhooks, "alert-webhook", []string{}, "Send the job's alerts as JSON to a webhook URL")
+	cmd.Flags().StringArrayVar(&f.slack, "alert-slack", []string{}, "Send the job's alerts to a Slack-compatible incoming webhook URL")
+	cmd.Flags().IntVar(&f.after, "alert-after", 0, "Consecutive failed runs before alerting (default: 1)")
+	cmd.Flags().BoolVar(&f.recovery, "alert-recovery", true, "Alert when the job succeeds again after alerting")
+	cmd.Flags().DurationVar(&f.repeat, "alert-repeat", 0, "Remind at this interval while the job keeps failing (default: never)")
+	cmd.Flags().BoolVar(&f.disabled, "no-alerts", false, "Send no alerts for the job")
+}
+
+// changed reports whether any alert flag is given on the command line
+func (f *alertFlags) changed(cmd *cobra.Command) bool {
+	for _, name := range alertFlagNames {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns a copy of the policy with the given flags applied, nil if
+// nothing is set. Channel flags replace the job's channels; given only
+// empty values they remove them, so the configured channels apply.
+func (f *alertFlags) apply(cmd *cobra.Command, policy *types.AlertPolicy) *types.AlertPolicy {
+	flags := cmd.Flags()
+
+	var updated types.AlertPolicy
+	if policy != nil {
+		updated = *policy
+	}
+
+	for _, name := range alertChannelFlags {
+		if flags.Changed(name) {
+			updated.Channels = nil
+			break
+		}
+	}
+	for _, name := range f.channels {
+		if name != "" {
+			updated.Channels = append(updated.Channels, types.AlertChannel{Name: name})
+		}
+	}
+	for _, url := range f.webhooks {
+		if url != "" {
+			updated.Channels = append(updated.Channels, types.AlertChannel{Type: types.ChannelWebhook, URL: url})
+		}
+	}
+	for _, url := range f.slack {
+		if url != "" {
+			updated.Channels = append(updated.Channels, types.AlertChannel{Type: types.ChannelSlack, URL: url})
+		}
+	}
+
+	if flags.Changed("alert-after") {
+		updated.After = f.after
+	}
+	if flags.Changed("alert-recovery") {
+		recovery := f.recovery
+		updated.Recovery = &recovery
+	}
+	if flags.Changed("alert-repeat") {
+		updated.Repeat = types.Duration(f.repeat)
+	}
+	if flags.Changed("no-alerts") {
+		updated.Disabled = f.disabled
+	}
+
+	if len(updated.Channels) == 0 && updated.After == 0 && updated.Recovery == nil && updated.Repeat == 0 && !updated.Disabled {
+		return nil
+	}
+	return &updated
+}
+
+// loadAlertDefaults reads the global alert policy from the configuration
+func loadAlertDefaults() (*types.AlertPolicy, error) {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Alerts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid alerts settings in %s: %v", config.FileName, err)
+	}
+	if cfg.Alerts != nil {
+		for _, channel := range cfg.Alerts.Channels {
+			if channel.IsReference() {
+				return nil, fmt.Errorf("invalid alerts settings in %s: channel %q needs a type", config.FileName, channel.Name)
+			}
+			if err := alert.ValidateTemplates(channel); err != nil {
+				return nil, fmt.Errorf("invalid alerts settings in %s: channel %s: %v", config.FileName, channel, err)
+			}
+		}
+	}
+	return cfg.Alerts, nil
+}
+
+// validateAlertPolicy checks a job's alert policy and that the channels it
+// refers to are configured
+func validateAlertPolicy(policy *types.AlertPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	for _, channel := range policy.Channels {
+		if err := alert.ValidateTemplates(channel); err != nil {
+			return fmt.Errorf("channel %s: %v", channel, err)
+		}
+	}
+
+	global, err := loadAlertDefaults()
+	if err != nil {
+		return err
+	}
+	if _, err := policy.WithDefaults(global); err != nil {
+		return fmt.Errorf("%v. Define it under \"alerts\" in %s", err, config.FileName)
+	}
+	return nil
+}
+
+// formatAlertPolicy describes a job's own alert policy without channel URLs
+func formatAlertPolicy(p *types.AlertPolicy) string {
+	if p == nil {
+		return ""
+	}
+	if p.Disabled {
+		return "disabled"
+	}
+
+	var parts []string
+	if p.After != 0 {
+		parts = append(parts, fmt.Sprintf("after %d failed runs", p.After))
+	}
+	if len(p.Channels) > 0 {
+		var channels []string
+		for _, channel := range p.Channels {
+			channels = append(channels, channel.String())
+		}
+		parts = append(parts, "to "+strings.Join(channels, ", "))
+	}
+	if p.Recovery != nil {
+		if *p.Recovery {
+			parts = append(parts, "on recovery")
+		} else {
+			parts = append(parts, "not on recovery")
+		}
+	}
+	if p.Repeat != 0 {
+		parts = append(parts, "every "+p.Repeat.String()+" while failing")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -4,12 +4,26 @@ import "github.com/spf13/cobra"
 
 // RegisterCommands adds all CLI commands to the root command
 func RegisterCommands(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().StringVar(&storageBackend, "storage", "", "Storage backend (json, sqlite) [default: from ~/.tempo/config.json, else json]")
+
+	rootCmd.PersistentFlags().BoolVar(&showSecrets, "show-secrets", false, "Print headers, URLs and bodies without redacting sensitive values")
+
 	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(editCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(renderCmd)
 	rootCmd.AddCommand(logsCmd)
 	rootCmd.AddCommand(removeCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(secretCmd)
+	rootCmd.AddCommand(alertCmd)
 }
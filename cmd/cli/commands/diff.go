@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"tempo/internal/jobfile"
+	"tempo/internal/types"
+)
+
+// diffContext is how many unchanged lines are shown around a change
+const diffContext = 2
+
+// jobYAML renders a single job as YAML, the format 'tempo edit' works on
+func jobYAML(job types.Job) (string, error) {
+	data, err := jobfile.Encode([]types.Job{job}, jobfile.FormatYAML)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sameJob reports whether two jobs are saved the same way. Comparing their
+// YAML ignores differences such as nil and empty header maps.
+func sameJob(a, b types.Job) bool {
+	yamlA, errA := jobYAML(a)
+	yamlB, errB := jobYAML(b)
+	return errA == nil && errB == nil && yamlA == yamlB
+}
+
+// printJobDiff prints the changes between two versions of a job as a line
+// diff of their YAML, with sensitive values redacted
+func printJobDiff(before, after types.Job) error {
+	redactor := outputRedactor()
+
+	old, err := jobYAML(redactor.Job(before))
+	if err != nil {
+		return err
+	}
+	updated, err := jobYAML(redactor.Job(after))
+	if err != nil {
+		return err
+	}
+
+	for _, line := range diffLines(splitLines(old), splitLines(updated)) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns a line diff of a and b, prefixing lines with "- ", "+ "
+// or "  ". Unchanged lines far from any change are collapsed into "...".
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+
+	return collapseUnchanged(lines)
+}
+
+// collapseUnchanged drops unchanged lines more than diffContext lines away from a change
+func collapseUnchanged(lines []string) []string {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if strings.HasPrefix(line, "  ") {
+			continue
+		}
+		for k := max(0, i-diffContext); k <= min(len(lines)-1, i+diffContext); k++ {
+			keep[k] = true
+		}
+	}
+
+	var collapsed []string
+	skipped := false
+	for i, line := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			collapsed = append(collapsed, "  ...")
+			skipped = false
+		}
+		collapsed = append(collapsed, line)
+	}
+	if skipped {
+		collapsed = append(collapsed, "  ...")
+	}
+	return collapsed
+}
@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"tempo/internal/jobfile"
+	"tempo/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit [job-id]",
+	Short: "Edit a job as YAML in your editor",
+	Long: `Open a job as YAML in $VISUAL or $EDITOR (default: vi). When the editor exits
+the job is validated and the changes are shown for confirmation before they
+are saved. Invalid changes can be fixed by editing again.
+
+The file uses the same format as 'tempo export --format yaml'.
+
+Examples:
+  tempo edit health-check
+  EDITOR=nano tempo edit health-check`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+var editYes bool
+
+func init() {
+	editCmd.Flags().BoolVarP(&editYes, "yes", "y", false, "Save the changes without asking for confirmation")
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	jobID := args[0]
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	job, exists := store.GetJob(jobID)
+	if !exists {
+		return fmt.Errorf("job '%s' not found", jobID)
+	}
+
+	content, err := jobYAML(job)
+	if err != nil {
+		return err
+	}
+
+	// The file holds unredacted values, so only the user may read it
+	file, err := os.CreateTemp("", "tempo-"+jobID+"-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(file.Name())
+	file.Close()
+
+	reader := bufio.NewReader(os.Stdin)
+	var edited types.Job
+	for {
+		if err := os.WriteFile(file.Name(), []byte(content), 0600); err != nil {
+			return fmt.Errorf("failed to write temporary file: %v", err)
+		}
+		if err := runEditor(file.Name()); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read edited job: %v", err)
+		}
+		content = string(data)
+
+		edited, err = parseEditedJob(data, jobID)
+		if err == nil {
+			break
+		}
+		fmt.Printf("Error: %v\n", err)
+		if !confirm(reader, "Edit again?", true) {
+			return fmt.Errorf("edit cancelled, job '%s' is unchanged", jobID)
+		}
+	}
+
+	if sameJob(job, edited) {
+		fmt.Printf("No changes to job '%s'\n", jobID)
+		return nil
+	}
+
+	if err := printJobDiff(job, edited); err != nil {
+		return err
+	}
+	if !editYes && !confirm(reader, "Save these changes?", false) {
+		fmt.Printf("Job '%s' is unchanged\n", jobID)
+		return nil
+	}
+
+	// Refuse to overwrite changes made while the editor was open
+	errChanged := fmt.Errorf("job '%s' was changed or removed while editing. Run 'tempo edit' again", jobID)
+	err = store.UpdateJob(jobID, func(current types.Job, exists bool) (types.Job, error) {
+		if !exists || !sameJob(current, job) {
+			return types.Job{}, errChanged
+		}
+		return edited, nil
+	})
+	if errors.Is(err, errChanged) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update job: %v", err)
+	}
+
+	fmt.Printf("✓ Updated job '%s'\n", jobID)
+	reloadScheduler()
+	return nil
+}
+
+// parseEditedJob decodes and validates the job written by the editor
+func parseEditedJob(data []byte, jobID string) (types.Job, error) {
+	jobs, err := jobfile.Decode(data, jobfile.FormatYAML)
+	if err != nil {
+		return types.Job{}, err
+	}
+	if len(jobs) != 1 {
+		return types.Job{}, fmt.Errorf("expected exactly one job, found %d", len(jobs))
+	}
+
+	job := jobs[0]
+	if job.ID != jobID {
+		return types.Job{}, fmt.Errorf("the job ID cannot be changed from '%s'", jobID)
+	}
+	if err := validateJob(job); err != nil {
+		return types.Job{}, err
+	}
+	return job, nil
+}
+
+// runEditor opens a file in the user's editor and waits for it to exit
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may be configured with arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	editorCmd := exec.Command(fields[0], append(fields[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %v", fields[0], err)
+	}
+	return nil
+}
+
+// confirm asks a yes/no question, returning the default for an empty answer
+func confirm(reader *bufio.Reader, question string, defaultYes bool) bool {
+	options := "[y/N]"
+	if defaultYes {
+		options = "[Y/n]"
+	}
+	fmt.Printf("%s %s: ", question, options)
+
+	answer, err := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if err != nil && answer == "" {
+		// No answer can be read, e.g. stdin is not a terminal
+		return false
+	}
+
+	switch answer {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,10 +1,9 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"tempo/internal/storage"
+	"tempo/internal/jobfile"
 
 	"github.com/spf13/cobra"
 )
@@ -12,12 +11,15 @@ import (
 var exportCmd = &cobra.Command{
 	Use:   "export [filename]",
 	Short: "Export job configurations",
-	Long: `Export all job configurations to a JSON file for backup or migration.
+	Long: `Export all job configurations to a JSON or YAML file for backup or migration.
+The format is taken from --format or the file extension. Use '-' to write to stdout.
 
 Examples:
   tempo export
   tempo export backup.json
-  tempo export --format yaml`,
+  tempo export jobs.yaml
+  tempo export - > backup.json
+  tempo export --format yaml -`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runExport,
 }
@@ -25,7 +27,7 @@ Examples:
 var exportFormat string
 
 func init() {
-	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "Export format (json, yaml)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "Export format (json, yaml) [default: from file extension, else json]")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
@@ -34,31 +36,34 @@ func runExport(cmd *cobra.Command, args []string) error {
 		filename = args[0]
 	}
 
-	store, err := storage.NewStorage("")
+	format := exportFormat
+	if format == "" {
+		format = jobfile.DetectFormat(filename)
+	}
+
+	store, err := openStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %v", err)
 	}
+	defer store.Close()
 
 	jobs := store.GetAllJobs()
 
-	var data []byte
-	var err2 error
+	data, err := jobfile.Encode(jobs, format)
+	if err != nil {
+		return err
+	}
 
-	switch exportFormat {
-	case "json":
-		data, err2 = json.MarshalIndent(jobs, "", "  ")
-		if err2 != nil {
-			return fmt.Errorf("failed to marshal JSON: %v", err2)
+	if filename == "-" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return fmt.Errorf("failed to write to stdout: %v", err)
 		}
-	case "yaml":
-		return fmt.Errorf("YAML export not implemented yet")
-	default:
-		return fmt.Errorf("unsupported format: %s", exportFormat)
+		fmt.Fprintf(os.Stderr, "✓ Exported %d jobs\n", len(jobs))
+		return nil
 	}
 
-	err2 = os.WriteFile(filename, data, 0644)
-	if err2 != nil {
-		return fmt.Errorf("failed to write file: %v", err2)
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
 	}
 
 	fmt.Printf("✓ Exported %d jobs to %s\n", len(jobs), filename)
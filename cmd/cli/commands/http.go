@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"tempo/internal/config"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// httpFlags are the HTTP client settings accepted by add, update and run
+type httpFlags struct {
+	timeout         time.Duration
+	followRedirects bool
+	maxRedirects    int
+	caCert          string
+	clientCert      string
+	clientKey       string
+	insecure        bool
+	proxy           string
+	version         string
+}
+
+var httpFlagNames = []string{"timeout", "follow-redirects", "max-redirects", "ca-cert", "client-cert", "client-key", "insecure", "proxy", "http-version"}
+
+func (f *httpFlags) register(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0, "Request timeout (default: 10s)")
+	cmd.Flags().BoolVar(&f.followRedirects, "follow-redirects", true, "Follow redirects, --follow-redirects=false returns the redirect response")
+	cmd.Flags().IntVar(&f.maxRedirects, "max-redirects", 0, "Maximum redirects to follow (default: 10)")
+	cmd.Flags().StringVar(&f.caCert, "ca-cert", "", "PEM file with the CA certificates to trust instead of the system ones")
+	cmd.Flags().StringVar(&f.clientCert, "client-cert", "", "PEM client certificate for mutual TLS")
+	cmd.Flags().StringVar(&f.clientKey, "client-key", "", "PEM key of --client-cert, if it is not in the certificate file")
+	cmd.Flags().BoolVar(&f.insecure, "insecure", false, "Accept any server certificate, e.g. for internal staging hosts")
+	cmd.Flags().StringVar(&f.proxy, "proxy", "", "HTTP, HTTPS or SOCKS5 proxy URL, 'none' to ignore HTTP_PROXY (default: from the environment)")
+	cmd.Flags().StringVar(&f.version, "http-version", "", "Force HTTP version '1.1' or '2' (default: negotiated)")
+}
+
+// changed reports whether any HTTP flag is given on the command line
+func (f *httpFlags) changed(cmd *cobra.Command) bool {
+	for _, name := range httpFlagNames {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns a copy of the settings with the given flags applied, nil if
+// nothing is set. Empty values remove a setting.
+func (f *httpFlags) apply(cmd *cobra.Command, settings *types.HTTPConfig) (*types.HTTPConfig, error) {
+	flags := cmd.Flags()
+
+	var updated types.HTTPConfig
+	if settings != nil {
+		updated = *settings
+	}
+
+	if flags.Changed("timeout") {
+		updated.Timeout = types.Duration(f.timeout)
+	}
+	if flags.Changed("follow-redirects") {
+		follow := f.followRedirects
+		updated.FollowRedirects = &follow
+	}
+	if flags.Changed("max-redirects") {
+		updated.MaxRedirects = f.maxRedirects
+	}
+	if flags.Changed("insecure") {
+		insecure := f.insecure
+		updated.InsecureSkipVerify = &insecure
+	}
+	if flags.Changed("proxy") {
+		updated.Proxy = f.proxy
+	}
+	if flags.Changed("http-version") {
+		updated.Version = strings.TrimPrefix(strings.ToLower(f.version), "http/")
+	}
+
+	// The scheduler may run in another directory
+	files := []struct {
+		name  string
+		value string
+		field *string
+	}{
+		{"ca-cert", f.caCert, &updated.CACert},
+		{"client-cert", f.clientCert, &updated.ClientCert},
+		{"client-key", f.clientKey, &updated.ClientKey},
+	}
+	for _, file := range files {
+		if !flags.Changed(file.name) {
+			continue
+		}
+		*file.field = ""
+		if file.value != "" {
+			path, err := filepath.Abs(file.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --%s path: %v", file.name, err)
+			}
+			*file.field = path
+		}
+	}
+
+	if updated.IsZero() {
+		return nil, nil
+	}
+	return &updated, nil
+}
+
+// loadHTTPDefaults reads the default HTTP client settings from the configuration
+func loadHTTPDefaults() (*types.HTTPConfig, error) {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.HTTP.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid http settings in %s: %v", config.FileName, err)
+	}
+	return cfg.HTTP, nil
+}
+
+// formatHTTPConfig describes the HTTP client settings a job makes
+func formatHTTPConfig(c *types.HTTPConfig) string {
+	if c == nil {
+		return ""
+	}
+
+	var parts []string
+	if c.Timeout != 0 {
+		parts = append(parts, "timeout "+c.Timeout.String())
+	}
+	if c.FollowRedirects != nil && !*c.FollowRedirects {
+		parts = append(parts, "no redirects")
+	} else if c.MaxRedirects != 0 {
+		parts = append(parts, fmt.Sprintf("up to %d redirects", c.MaxRedirects))
+	}
+	if c.CACert != "" {
+		parts = append(parts, "CA "+c.CACert)
+	}
+	if c.ClientCert != "" {
+		parts = append(parts, "client cert "+c.ClientCert)
+	}
+	if c.SkipVerify() {
+		parts = append(parts, "insecure")
+	}
+	if c.Proxy != "" {
+		parts = append(parts, "proxy "+c.Proxy)
+	}
+	if c.Version != "" {
+		parts = append(parts, "HTTP/"+c.Version)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,11 +1,11 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"tempo/internal/storage"
-	"tempo/internal/types"
+	"tempo/internal/jobfile"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -13,11 +13,14 @@ import (
 var importCmd = &cobra.Command{
 	Use:   "import [filename]",
 	Short: "Import job configurations",
-	Long: `Import job configurations from a JSON file.
+	Long: `Import job configurations from a JSON or YAML file. The format is taken from
+--format or the file extension. YAML files may contain several documents,
+each holding one job or a list of jobs. Use '-' to read from stdin.
 
 Examples:
   tempo import backup.json
-  tempo import --format yaml jobs.yaml`,
+  tempo import jobs.yaml
+  tempo import --format yaml - < jobs.yaml`,
 	Args: cobra.ExactArgs(1),
 	RunE: runImport,
 }
@@ -25,35 +28,53 @@ Examples:
 var importFormat string
 
 func init() {
-	importCmd.Flags().StringVarP(&importFormat, "format", "f", "json", "Import format (json, yaml)")
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "", "Import format (json, yaml) [default: from file extension, else json]")
 }
 
 func runImport(cmd *cobra.Command, args []string) error {
 	filename := args[0]
 
-	data, err := os.ReadFile(filename)
+	format := importFormat
+	if format == "" {
+		format = jobfile.DetectFormat(filename)
+	}
+
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to read file: %v", err)
 	}
 
-	var jobs []types.Job
+	jobs, err := jobfile.Decode(data, format)
+	if err != nil {
+		return err
+	}
 
-	switch importFormat {
-	case "json":
-		err = json.Unmarshal(data, &jobs)
-		if err != nil {
-			return fmt.Errorf("failed to parse JSON: %v", err)
+	now := time.Now().Truncate(time.Second)
+	for i, job := range jobs {
+		// Intervals without an anchor count from the import
+		if job.Every != 0 && job.Anchor == nil {
+			jobs[i].Anchor = &now
+			job = jobs[i]
+		}
+		if job.ID == "" {
+			return fmt.Errorf("every imported job needs an ID")
+		}
+		if err := validateJob(job); err != nil {
+			return fmt.Errorf("job '%s': %v", job.ID, err)
 		}
-	case "yaml":
-		return fmt.Errorf("YAML import not implemented yet")
-	default:
-		return fmt.Errorf("unsupported format: %s", importFormat)
 	}
 
-	store, err := storage.NewStorage("")
+	store, err := openStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %v", err)
 	}
+	defer store.Close()
 
 	// Import jobs to storage
 	for _, job := range jobs {
@@ -63,5 +84,6 @@ func runImport(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Imported %d jobs from %s\n", len(jobs), filename)
+	reloadScheduler()
 	return nil
 }
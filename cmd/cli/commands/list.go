@@ -2,7 +2,9 @@ package commands
 
 import (
 	"fmt"
-	"tempo/internal/storage"
+	"strings"
+	"tempo/internal/types"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -25,10 +27,11 @@ func init() {
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	store, err := storage.NewStorage("")
+	store, err := openStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %v", err)
 	}
+	defer store.Close()
 
 	jobs := store.GetAllJobs()
 
@@ -44,20 +47,69 @@ func runList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Show live schedule information when a scheduler is running
+	scheduled := make(map[string]types.ScheduledJob)
+	if client, err := schedulerClient(); err == nil {
+		if loaded, err := client.Jobs(); err == nil {
+			for _, s := range loaded {
+				scheduled[s.Job.ID] = s
+			}
+		}
+	}
+
+	now := time.Now()
 	fmt.Printf("Found %d job(s):\n\n", len(jobs))
 	for _, job := range jobs {
+		job = outputRedactor().Job(job)
 		fmt.Printf("ID: %s\n", job.ID)
+		state := jobState(job, now)
+		if s, ok := scheduled[job.ID]; ok && !s.NextRun.IsZero() {
+			state += ", next run: " + s.NextRun.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("  State: %s\n", state)
 		fmt.Printf("  Method: %s\n", job.Method)
 		fmt.Printf("  URL: %s\n", job.URL)
-		fmt.Printf("  Schedule: %s\n", job.CronExpr)
+		fmt.Printf("  Schedule: %s\n", job.Schedule())
 		if job.Body != "" {
 			fmt.Printf("  Body: %.50s...\n", job.Body)
 		}
 		if len(job.Headers) > 0 {
 			fmt.Printf("  Headers: %v\n", job.Headers)
 		}
+		if len(job.Tags) > 0 {
+			fmt.Printf("  Tags: %s\n", strings.Join(job.Tags, ", "))
+		}
+		if job.Overlap != "" {
+			fmt.Printf("  Overlap: %s\n", formatOverlap(job))
+		}
+		if settings := formatHTTPConfig(job.HTTP); settings != "" {
+			fmt.Printf("  HTTP: %s\n", settings)
+		}
+		if job.Retry != nil {
+			fmt.Printf("  Retry: %s\n", formatRetryPolicy(job.Retry))
+		}
+		if job.Assert != nil {
+			fmt.Printf("  Assert: %s\n", formatAssertions(job.Assert))
+		}
+		if policy := formatAlertPolicy(job.Alert); policy != "" {
+			fmt.Printf("  Alerts: %s\n", policy)
+		}
 		fmt.Println()
 	}
 
 	return nil
 }
+
+// jobState describes whether a job runs on schedule, is paused or has completed
+func jobState(job types.Job, now time.Time) string {
+	switch {
+	case job.CompletedAt != nil:
+		return "completed " + job.CompletedAt.Local().Format("2006-01-02 15:04:05")
+	case !job.IsPaused(now):
+		return "active"
+	case job.PausedUntil != nil:
+		return "paused until " + job.PausedUntil.Local().Format("2006-01-02 15:04:05")
+	default:
+		return "paused"
+	}
+}
@@ -1,7 +1,11 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"tempo/internal/control"
+	"tempo/internal/storage"
+	"tempo/internal/types"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -39,21 +43,169 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		jobID = args[0]
 	}
 
-	// TODO: Implement log viewing from storage
+	filter := storage.HistoryFilter{
+		JobID: jobID,
+		Limit: limit,
+	}
+	if since != "" {
+		sinceTime, err := parseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.Since = sinceTime
+	}
+
+	executions, err := queryExecutions(filter)
+	if err != nil {
+		return err
+	}
+
 	if jobID != "" {
 		fmt.Printf("Logs for job '%s':\n", jobID)
 	} else {
 		fmt.Println("All job logs:")
 	}
 
-	// Placeholder log entries
-	fmt.Printf("[%s] INFO: No logs available yet\n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Printf("[%s] INFO: Use 'tempo run' to test jobs and generate logs\n", time.Now().Format("2006-01-02 15:04:05"))
+	if len(executions) == 0 && !follow {
+		fmt.Println("No executions recorded yet.")
+		fmt.Println("Use 'tempo run' or 'tempo start' to execute jobs and generate logs.")
+	}
+	for _, exec := range executions {
+		printExecution(exec)
+	}
 
 	if follow {
-		fmt.Println("Following logs (press Ctrl+C to stop)...")
-		// TODO: Implement real-time log following
+		return followLogs(jobID)
+	}
+
+	return nil
+}
+
+// queryExecutions asks the running scheduler for recent executions and
+// falls back to reading the history file when none is running
+func queryExecutions(filter storage.HistoryFilter) ([]types.Execution, error) {
+	if client, err := schedulerClient(); err == nil {
+		executions, err := client.Executions(filter.JobID, filter.Since, filter.Limit)
+		if err == nil {
+			return executions, nil
+		}
+		if !errors.Is(err, control.ErrNotRunning) {
+			return nil, fmt.Errorf("failed to read history: %v", err)
+		}
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	executions, err := store.QueryExecutions(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %v", err)
+	}
+	return executions, nil
+}
+
+// followLogs streams live events from the running scheduler until interrupted
+func followLogs(jobID string) error {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return err
+	}
+
+	client := control.NewClient(dataDir)
+
+	fmt.Println("Following logs (press Ctrl+C to stop)...")
+	err = client.Follow(jobID, func(event types.Event) error {
+		printEvent(event)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	fmt.Println("Scheduler stopped.")
 	return nil
 }
+
+// printEvent writes a live scheduler event as one log line
+func printEvent(event types.Event) {
+	level := "INFO"
+	switch event.Type {
+	case types.EventFailure:
+		level = "ERROR"
+	case types.EventRetry, types.EventSkipped, types.EventCancelled:
+		level = "WARN"
+	}
+
+	fmt.Printf("[%s] %s: %s %s attempt=%d",
+		event.Time.Local().Format("2006-01-02 15:04:05"),
+		level,
+		event.JobID,
+		event.Type,
+		event.Attempt,
+	)
+	switch event.Type {
+	case types.EventRetry:
+		fmt.Printf(" status=%d delay=%dms", event.StatusCode, event.DelayMs)
+	case types.EventSuccess, types.EventFailure, types.EventCancelled:
+		fmt.Printf(" status=%d latency=%dms", event.StatusCode, event.LatencyMs)
+	}
+	if event.Error != "" {
+		fmt.Printf(" error=%q", outputRedactor().String(event.Error))
+	}
+	fmt.Println()
+}
+
+// printExecution writes a single history record as one log line
+func printExecution(exec types.Execution) {
+	level := "INFO"
+	switch exec.Status {
+	case types.StatusSuccess:
+	case types.StatusSkipped, types.StatusCancelled:
+		level = "WARN"
+	default:
+		level = "ERROR"
+	}
+
+	fmt.Printf("[%s] %s: %s %s attempt=%d status=%d latency=%dms size=%dB trigger=%s",
+		exec.StartedAt.Local().Format("2006-01-02 15:04:05"),
+		level,
+		exec.JobID,
+		exec.Status,
+		exec.Attempt,
+		exec.StatusCode,
+		exec.LatencyMs,
+		exec.ResponseSize,
+		exec.Trigger,
+	)
+	if !exec.ScheduledAt.IsZero() && exec.Trigger == types.TriggerSchedule {
+		fmt.Printf(" scheduled=%s", exec.ScheduledAt.Local().Format("15:04:05"))
+	}
+	if exec.QueueMs > 0 {
+		fmt.Printf(" queued=%dms", exec.QueueMs)
+	}
+	if exec.Error != "" {
+		fmt.Printf(" error=%q", outputRedactor().String(exec.Error))
+	}
+	fmt.Println()
+}
+
+// timeLayouts are the absolute time formats accepted by --since and --until, in local time
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// parseSince accepts a relative duration ('1h', '30m') or an absolute date/time
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since value: %s. Use a duration like '1h' or a date like '2024-01-01'", value)
+}
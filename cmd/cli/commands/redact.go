@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"tempo/internal/config"
+	"tempo/internal/redact"
+	"tempo/internal/storage"
+)
+
+var (
+	showSecrets bool
+
+	redactorOnce sync.Once
+	redactor     *redact.Redactor
+)
+
+// outputRedactor returns the redactor for everything the CLI prints. It
+// applies the default rules plus those in the configuration, unless
+// --show-secrets is set.
+func outputRedactor() *redact.Redactor {
+	redactorOnce.Do(func() {
+		if showSecrets {
+			redactor = redact.Disabled()
+			return
+		}
+
+		var err error
+		redactor, err = loadRedactor()
+		if err != nil {
+			fmt.Printf("Warning: %v, using the default redaction rules\n", err)
+		}
+	})
+	return redactor
+}
+
+// loadRedactor builds a redactor from the configuration in the data directory
+func loadRedactor() (*redact.Redactor, error) {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return redact.New(cfg.Redact)
+}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"tempo/internal/storage"
 
 	"github.com/spf13/cobra"
 )
@@ -26,16 +25,18 @@ func init() {
 }
 
 func runRemove(cmd *cobra.Command, args []string) error {
-	store, err := storage.NewStorage("")
+	store, err := openStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %v", err)
 	}
+	defer store.Close()
 
 	if removeAll {
 		if err := store.RemoveAllJobs(); err != nil {
 			return fmt.Errorf("failed to remove all jobs: %v", err)
 		}
 		fmt.Println("✓ Removed all jobs")
+		reloadScheduler()
 		return nil
 	}
 
@@ -50,5 +51,6 @@ func runRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✓ Removed job '%s'\n", jobID)
+	reloadScheduler()
 	return nil
 }
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"tempo/internal/secrets"
+	"tempo/internal/service"
+	"tempo/internal/templating"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render [job-id]",
+	Short: "Show a job's request with its templates expanded",
+	Long: `Render the templates in a job's URL, headers and body without sending the request.
+
+The run number is the one the job's next run would get and secrets are shown
+as ********. Use --at to preview a run scheduled for another time.
+
+Examples:
+  tempo render daily-report
+  tempo render daily-report --at 2025-01-31T09:00:00Z`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRender,
+}
+
+var renderAt string
+
+func init() {
+	renderCmd.Flags().StringVar(&renderAt, "at", "", "Scheduled time to render for, in RFC 3339 format (default: now)")
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	jobID := args[0]
+
+	scheduledAt := time.Now()
+	if renderAt != "" {
+		at, err := time.Parse(time.RFC3339, renderAt)
+		if err != nil {
+			return fmt.Errorf("invalid --at time %q, use RFC 3339 (e.g. 2025-01-31T09:00:00Z)", renderAt)
+		}
+		scheduledAt = at
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	job, exists := store.GetJob(jobID)
+	if !exists {
+		return fmt.Errorf("job '%s' not found", jobID)
+	}
+
+	runs, err := service.CountRuns(store, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %v", err)
+	}
+
+	// Show where secrets go without revealing them
+	secretStore, err := secrets.Open("")
+	if err != nil {
+		return err
+	}
+
+	request, used, err := service.RenderJob(job, runs+1, templating.NewUUID(), scheduledAt, maskedSecrets{secretStore})
+	// Secrets already show as masked, environment values are redacted
+	used = slices.DeleteFunc(used, func(value string) bool { return value == maskedSecret })
+	redactor := outputRedactor().WithValues(used...)
+	if err != nil {
+		return redactor.Error(err)
+	}
+
+	request = redactor.Job(request)
+	fmt.Printf("%s %s\n", request.Method, request.URL)
+
+	keys := make([]string, 0, len(request.Headers))
+	for key := range request.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("%s: %s\n", key, request.Headers[key])
+	}
+
+	if request.Body != "" {
+		fmt.Println()
+		fmt.Println(request.Body)
+	}
+
+	return nil
+}
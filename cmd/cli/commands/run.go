@@ -1,11 +1,15 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"tempo/internal/control"
 	"tempo/internal/service"
-	"tempo/internal/storage"
+	"tempo/internal/templating"
 	"tempo/internal/types"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -15,8 +19,12 @@ var runCmd = &cobra.Command{
 	Short: "Run a job immediately",
 	Long: `Execute a webhook job immediately for testing purposes.
 
+HTTP client flags such as --timeout or --proxy override the job's settings
+for this run only.
+
 Examples:
   tempo run health-check
+  tempo run health-check --insecure --http-version 1.1
   tempo run --url "https://api.example.com/test" --method POST`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runExecute,
@@ -27,13 +35,15 @@ var (
 	runMethod  string
 	runBody    string
 	runHeaders []string
+	runHTTP    httpFlags
 )
 
 func init() {
 	runCmd.Flags().StringVarP(&runURL, "url", "u", "", "Webhook URL (for one-off execution)")
 	runCmd.Flags().StringVarP(&runMethod, "method", "m", "GET", "HTTP method")
 	runCmd.Flags().StringVarP(&runBody, "body", "b", "", "Request body")
-	runCmd.Flags().StringSliceVarP(&runHeaders, "header", "H", []string{}, "HTTP headers (format: 'Key=Value')")
+	runCmd.Flags().StringArrayVarP(&runHeaders, "header", "H", []string{}, "HTTP headers (format: 'Key=Value')")
+	runHTTP.register(runCmd)
 }
 
 func runExecute(cmd *cobra.Command, args []string) error {
@@ -44,26 +54,30 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		jobID := args[0]
 
-		store, err := storage.NewStorage("")
+		store, err := openStorage()
 		if err != nil {
 			return fmt.Errorf("failed to initialize storage: %v", err)
 		}
+		defer store.Close()
 
 		job, exists := store.GetJob(jobID)
 		if !exists {
 			return fmt.Errorf("job '%s' not found", jobID)
 		}
 
-		fmt.Printf("Running job '%s'...\n", jobID)
-		fmt.Printf("Executing: %s %s\n", job.Method, job.URL)
-		if job.Body != "" {
-			fmt.Printf("Body: %.50s...\n", job.Body)
-		}
-		if len(job.Headers) > 0 {
-			fmt.Printf("Headers: %v\n", job.Headers)
+		if runHTTP.changed(cmd) {
+			if job.HTTP, err = runHTTP.apply(cmd, job.HTTP); err != nil {
+				return err
+			}
+		} else if handled, err := triggerInScheduler(jobID); handled {
+			// A running scheduler executed it, so it shows up in its events
+			return err
 		}
 
-		err = service.CallWebhook(job)
+		fmt.Printf("Running job '%s'...\n", jobID)
+		printRequest(job)
+
+		err = executeAndRecord(job)
 		if err != nil {
 			fmt.Printf("❌ Error: %v\n", err)
 			return err
@@ -88,28 +102,132 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		headers[parts[0]] = parts[1]
 	}
 
+	settings, err := runHTTP.apply(cmd, nil)
+	if err != nil {
+		return err
+	}
+
 	job := types.Job{
 		ID:      "one-off",
 		URL:     runURL,
 		Method:  runMethod,
 		Body:    runBody,
 		Headers: headers,
+		HTTP:    settings,
+	}
+
+	printRequest(job)
+
+	err = executeAndRecord(job)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	fmt.Println("✅ Webhook executed successfully")
+	return nil
+}
+
+// triggerInScheduler runs the job through the running scheduler's control API.
+// It reports whether a scheduler handled the request.
+func triggerInScheduler(jobID string) (bool, error) {
+	client, err := schedulerClient()
+	if err != nil {
+		return false, nil
+	}
+
+	exec, err := client.Trigger(jobID)
+	if errors.Is(err, control.ErrNotRunning) {
+		return false, nil
+	}
+	if err != nil {
+		fmt.Printf("Warning: running scheduler could not trigger job: %v\n", err)
+		return false, nil
 	}
 
+	fmt.Printf("Triggered job '%s' in the running scheduler\n", jobID)
+	fmt.Printf("Status: %d, latency: %dms, response size: %d bytes, attempts: %d\n", exec.StatusCode, exec.LatencyMs, exec.ResponseSize, exec.Attempt)
+	printAssertionFailures(outputRedactor().Execution(exec).Failures)
+	if exec.Error != "" {
+		message := outputRedactor().String(exec.Error)
+		fmt.Printf("❌ Error: %v\n", message)
+		return true, errors.New(message)
+	}
+
+	fmt.Println("✅ Job executed successfully")
+	return true, nil
+}
+
+// printAssertionFailures lists the checks a response failed with expected and actual values
+func printAssertionFailures(failures []types.AssertionFailure) {
+	for _, failure := range failures {
+		fmt.Printf("  ✗ %s: expected %s, got %s\n", failure.Check, failure.Expected, failure.Actual)
+	}
+}
+
+// printRequest shows the request about to be sent with sensitive values redacted
+func printRequest(job types.Job) {
+	job = outputRedactor().Job(job)
+
 	fmt.Printf("Executing: %s %s\n", job.Method, job.URL)
 	if job.Body != "" {
 		fmt.Printf("Body: %.50s...\n", job.Body)
 	}
-	if len(headers) > 0 {
-		fmt.Printf("Headers: %v\n", headers)
+	if len(job.Headers) > 0 {
+		fmt.Printf("Headers: %v\n", job.Headers)
+	}
+	if settings := formatHTTPConfig(job.HTTP); settings != "" {
+		fmt.Printf("HTTP: %s\n", settings)
 	}
+}
 
-	err := service.CallWebhook(job)
+// executeAndRecord renders the job's templates, runs it once and saves the
+// attempt to the execution history
+func executeAndRecord(job types.Job) error {
+	store, herr := openStorage()
+	if herr != nil {
+		fmt.Printf("Warning: failed to initialize storage: %v\n", herr)
+	} else {
+		defer store.Close()
+	}
+
+	var run int64 = 1
+	if store != nil && templating.HasTemplates(job) {
+		if runs, err := service.CountRuns(store, job.ID); err == nil {
+			run = runs + 1
+		}
+	}
+
+	httpDefaults, err := loadHTTPDefaults()
 	if err != nil {
-		fmt.Printf("❌ Error: %v\n", err)
 		return err
 	}
 
-	fmt.Println("✅ Webhook executed successfully")
-	return nil
+	now := time.Now()
+	runID := templating.NewUUID()
+	request, used, err := service.RenderJob(job, run, runID, now, &secretResolver{prompt: true})
+	redactor := outputRedactor().WithValues(used...)
+	if err != nil {
+		return fmt.Errorf("failed to render job: %v", redactor.Error(err))
+	}
+	request.HTTP = job.HTTP.WithDefaults(httpDefaults)
+
+	exec, err := service.Execute(context.Background(), request, now, types.TriggerManual)
+	exec.RunID = runID
+	fmt.Printf("Status: %d, latency: %dms, response size: %d bytes\n", exec.StatusCode, exec.LatencyMs, exec.ResponseSize)
+	printAssertionFailures(redactor.Execution(exec).Failures)
+
+	// History is redacted even with --show-secrets
+	history, _ := loadRedactor()
+	exec = history.WithValues(used...).Execution(exec)
+	err = redactor.Error(err)
+
+	if store == nil {
+		return err
+	}
+	if herr := store.RecordExecution(exec); herr != nil {
+		fmt.Printf("Warning: failed to record execution: %v\n", herr)
+	}
+
+	return err
 }
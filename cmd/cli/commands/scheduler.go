@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"tempo/internal/control"
+	"tempo/internal/storage"
+)
+
+// schedulerClient returns a client for the control API of the running scheduler.
+// Its calls fail with control.ErrNotRunning when no scheduler is running.
+func schedulerClient() (*control.Client, error) {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return nil, err
+	}
+	return control.NewClient(dataDir), nil
+}
+
+// reloadScheduler makes a running scheduler pick up changes to the job storage
+func reloadScheduler() {
+	client, err := schedulerClient()
+	if err != nil {
+		return
+	}
+
+	summary, err := client.Reload()
+	if errors.Is(err, control.ErrNotRunning) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to reload running scheduler: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✓ Scheduler reloaded (%d added, %d updated, %d removed)\n",
+		len(summary.Added), len(summary.Updated), len(summary.Removed))
+}
@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"tempo/internal/secrets"
+	"tempo/internal/storage"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage encrypted secrets used by jobs",
+	Long: `Store tokens and API keys encrypted in ~/.tempo/secrets.json and reference them
+from a job's URL, headers or body with {{ secret "name" }}. Secrets are only
+resolved when the job is executed.
+
+The encryption key is read from TEMPO_SECRET_KEY, the file named by
+TEMPO_SECRET_KEY_FILE or ~/.tempo/secret.key, and is otherwise asked for.
+
+Examples:
+  tempo secret set warehouse_key
+  tempo add sync --url "https://api.example.com/sync" --header 'API-Key={{ secret "warehouse_key" }}' --schedule "0 0 * * * *"
+  tempo secret list`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set [name] [value]",
+	Short: "Create or replace a secret",
+	Long: `Encrypt and store a secret. Without a value argument the value is read from
+standard input, which keeps it out of your shell history.
+
+Examples:
+  tempo secret set warehouse_key
+  echo -n "$TOKEN" | tempo secret set api_token`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSecretSet,
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get [name]",
+	Short: "Print a secret's value",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSecretGet,
+}
+
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored secrets without their values",
+	Args:  cobra.NoArgs,
+	RunE:  runSecretList,
+}
+
+var secretRemoveCmd = &cobra.Command{
+	Use:     "rm [name]",
+	Aliases: []string{"remove"},
+	Short:   "Delete a secret",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runSecretRemove,
+}
+
+func init() {
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretGetCmd)
+	secretCmd.AddCommand(secretListCmd)
+	secretCmd.AddCommand(secretRemoveCmd)
+}
+
+func runSecretSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	store, err := unlockSecrets(true)
+	if err != nil {
+		return err
+	}
+
+	var value string
+	if len(args) > 1 {
+		value = args[1]
+	} else if value, err = readSecretValue(name); err != nil {
+		return err
+	}
+
+	if err := store.Set(name, value); err != nil {
+		return fmt.Errorf("failed to save secret: %v", err)
+	}
+
+	fmt.Printf("✓ Saved secret '%s'\n", name)
+	return nil
+}
+
+func runSecretGet(cmd *cobra.Command, args []string) error {
+	store, err := unlockSecrets(true)
+	if err != nil {
+		return err
+	}
+
+	value, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func runSecretList(cmd *cobra.Command, args []string) error {
+	store, err := secrets.Open("")
+	if err != nil {
+		return err
+	}
+
+	infos, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(infos) == 0 {
+		fmt.Println("No secrets stored. Use 'tempo secret set' to add one.")
+		return nil
+	}
+
+	fmt.Printf("%-30s %s\n", "NAME", "UPDATED")
+	for _, info := range infos {
+		fmt.Printf("%-30s %s\n", info.Name, info.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+func runSecretRemove(cmd *cobra.Command, args []string) error {
+	store, err := unlockSecrets(true)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Remove(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Removed secret '%s'\n", args[0])
+	return nil
+}
+
+// unlockSecrets opens the secrets store and unlocks it with the configured
+// key. With prompt set the key is asked for on a terminal if none is
+// configured, twice when the store is being created.
+func unlockSecrets(prompt bool) (*secrets.Store, error) {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := secrets.Open(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok, err := secrets.LoadKey(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if !prompt || !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("%w: set %s or create %s", secrets.ErrLocked, secrets.EnvKey, filepath.Join(dataDir, secrets.KeyFileName))
+		}
+		if key, err = promptSecretKey(!store.Exists()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := store.Unlock(key); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// promptSecretKey asks for the secret key without echoing it
+func promptSecretKey(confirm bool) (string, error) {
+	key, err := readHidden("Secret key: ")
+	if err != nil {
+		return "", err
+	}
+
+	if confirm {
+		again, err := readHidden("Repeat secret key: ")
+		if err != nil {
+			return "", err
+		}
+		if again != key {
+			return "", errors.New("secret keys do not match")
+		}
+	}
+	return key, nil
+}
+
+// readSecretValue reads a secret's value from a terminal prompt or standard input
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return readHidden(fmt.Sprintf("Value for '%s': ", name))
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret value: %v", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func readHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// secretResolver unlocks the secrets store the first time a job references a
+// secret, so the key is only needed by jobs that use one
+type secretResolver struct {
+	mutex  sync.Mutex
+	store  *secrets.Store
+	prompt bool
+}
+
+func (r *secretResolver) Secret(name string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.store == nil {
+		store, err := unlockSecrets(r.prompt)
+		if err != nil {
+			return "", err
+		}
+		r.store = store
+	}
+
+	return r.store.Get(name)
+}
+
+// maskedSecret is shown by 'tempo render' in place of a secret's value
+const maskedSecret = "********"
+
+// maskedSecrets stands in for secret values in output, failing only for
+// secrets that do not exist
+type maskedSecrets struct {
+	store *secrets.Store
+}
+
+func (m maskedSecrets) Secret(name string) (string, error) {
+	infos, err := m.store.List()
+	if err != nil {
+		return "", err
+	}
+	for _, info := range infos {
+		if info.Name == name {
+			return maskedSecret, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", secrets.ErrNotFound, name)
+}
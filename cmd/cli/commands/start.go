@@ -1,14 +1,34 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
+	"tempo/internal/alert"
+	"tempo/internal/config"
+	"tempo/internal/control"
+	"tempo/internal/daemon"
+	"tempo/internal/events"
+	"tempo/internal/logging"
+	"tempo/internal/metrics"
+	"tempo/internal/secrets"
 	"tempo/internal/service"
 	"tempo/internal/storage"
+	"tempo/internal/tracing"
+	"tempo/internal/web"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var startCmd = &cobra.Command{
@@ -16,60 +36,440 @@ var startCmd = &cobra.Command{
 	Short: "Start the webhook scheduler",
 	Long: `Start the webhook scheduler to execute configured jobs according to their schedules.
 
+Without --foreground the scheduler detaches from the terminal and runs in the
+background, writing its output to ~/.tempo/tempo.log. Use 'tempo status' and
+'tempo stop' to manage it.
+
+--http serves a web dashboard on the given address, e.g. '8080' for
+127.0.0.1:8080, to see, run, pause, create and edit jobs and browse their
+history from a browser. Open it with the printed link, which carries its
+access token. Binding other than loopback addresses requires dashboard.token
+in ~/.tempo/config.json.
+
+--metrics exposes Prometheus metrics at /metrics on the given address, e.g.
+':9090' or '127.0.0.1:9090'.
+
+Logs are written as text or, with --log-format json, one JSON object per line.
+A foreground scheduler logs to stderr unless --log-file is given. Log files are
+rotated when they reach --log-max-size.
+
+Runs are traced with OpenTelemetry when OTEL_EXPORTER_OTLP_ENDPOINT or
+OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, and webhooks receive the trace
+context in a W3C traceparent header.
+
 Examples:
   tempo start --foreground
-  tempo start --daemon`,
+  tempo start
+  tempo start --http 127.0.0.1:8080
+  tempo start --metrics :9090
+  tempo start --log-format json --log-level debug`,
 	RunE: runStart,
 }
 
-var foreground bool
+var (
+	foreground  bool
+	metricsAddr string
+	httpAddr    string
+	logFormat   string
+	logLevel    string
+	logFile     string
+	logMaxSize  int
+	logMaxFiles int
+)
+
+// daemonStartTimeout is how long 'tempo start' waits for a background scheduler to come up
+const daemonStartTimeout = 5 * time.Second
+
+// tracingShutdownTimeout is how long a stopping scheduler tries to export its last spans
+const tracingShutdownTimeout = 5 * time.Second
 
 func init() {
-	startCmd.Flags().BoolVarP(&foreground, "foreground", "f", false, "Run in foreground mode (default)")
+	startCmd.Flags().BoolVarP(&foreground, "foreground", "f", false, "Run in foreground mode instead of as a background daemon")
+	startCmd.Flags().StringVar(&httpAddr, "http", "", "Serve the web dashboard on this address, e.g. '8080' for 127.0.0.1:8080 (default: off)")
+	startCmd.Flags().StringVar(&metricsAddr, "metrics", "", "Serve Prometheus metrics at /metrics on this address, e.g. ':9090' (default: off)")
+	startCmd.Flags().StringVar(&logFormat, "log-format", logging.FormatText, "Log format (text, json)")
+	startCmd.Flags().StringVar(&logLevel, "log-level", "info", "Minimum level of logged messages (debug, info, warn, error)")
+	startCmd.Flags().StringVar(&logFile, "log-file", "", "Write logs to this file (default: stderr, ~/.tempo/tempo.log in background mode)")
+	startCmd.Flags().IntVar(&logMaxSize, "log-max-size", logging.DefaultMaxSize>>20, "Rotate the log file when it reaches this many megabytes, 0 to never rotate")
+	startCmd.Flags().IntVar(&logMaxFiles, "log-max-files", logging.DefaultMaxFiles, "Number of rotated log files to keep")
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
-	fmt.Println("🚀 Starting Tempo Scheduler...")
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return err
+	}
+
+	if err := (logging.Options{Format: logFormat, Level: logLevel}).Validate(); err != nil {
+		return err
+	}
+	if logMaxSize < 0 {
+		return fmt.Errorf("--log-max-size must not be negative")
+	}
+	if logMaxFiles < 1 {
+		return fmt.Errorf("--log-max-files must be at least 1")
+	}
+
+	if !foreground && os.Getenv(daemon.EnvChild) == "" {
+		return startDaemon(dataDir)
+	}
+
+	// Refuse to run next to another scheduler
+	pidFile, err := daemon.AcquirePIDFile(dataDir)
+	if err != nil {
+		return err
+	}
+	defer pidFile.Release()
+
+	logger, closeLog, err := openLogger()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	// Packages without a logger of their own, and the standard log package, log here too
+	slog.SetDefault(logger)
+
+	logger.Info("Starting scheduler", "pid", os.Getpid())
 
 	// Load jobs from storage
-	store, err := storage.NewStorage("")
+	store, err := openStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %v", err)
 	}
+	defer store.Close()
+
+	// Unlock existing secrets up front so a missing or wrong key shows now
+	// rather than when a job first runs
+	secretStore, err := secrets.Open(dataDir)
+	if err != nil {
+		return err
+	}
+	// 'tempo start' hands a background scheduler the key it asked for
+	secretKey, err := daemon.ReadSecret()
+	if err != nil {
+		return err
+	}
+	resolver := &secretResolver{}
+	if secretStore.Exists() {
+		if len(secretKey) > 0 {
+			err = secretStore.Unlock(string(secretKey))
+			if err == nil {
+				resolver.store = secretStore
+			}
+		} else {
+			resolver.store, err = unlockSecrets(true)
+		}
+		if errors.Is(err, secrets.ErrLocked) {
+			logger.Warn("Jobs that use secrets will fail", logging.Err(err))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Logs and history are always redacted, --show-secrets only affects the CLI
+	redactor, err := loadRedactor()
+	if err != nil {
+		return err
+	}
+
+	httpDefaults, err := loadHTTPDefaults()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(dataDir)
+	if err != nil {
+		return err
+	}
+	limits := cfg.Execution
+	if err := limits.Validate(); err != nil {
+		return fmt.Errorf("invalid execution settings in %s: %v", config.FileName, err)
+	}
+	if limits == nil {
+		limits = &config.Execution{}
+	}
+
+	alerts, err := loadAlertDefaults()
+	if err != nil {
+		return err
+	}
+	notifier, err := alert.New(dataDir, alerts, redactor, resolver, logger)
+	if err != nil {
+		return err
+	}
+
+	// Export traces if the OTEL_* environment variables ask for it
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("Not all traces were exported", logging.Err(err))
+		}
+	}()
+	if tracing.Enabled() {
+		logger.Info("Exporting traces over OTLP")
+	}
 
 	jobs := store.GetAllJobs()
-	scheduler := service.NewScheduler()
+	bus := events.NewBus()
+	options := []service.Option{
+		service.WithStorage(store),
+		service.WithHistory(store),
+		service.WithEvents(bus),
+		service.WithSecrets(resolver),
+		service.WithRedactor(redactor),
+		service.WithHTTPDefaults(httpDefaults),
+		service.WithWorkers(limits.Workers, limits.QueueSize),
+		service.WithMaxConnsPerHost(limits.MaxConnsPerHost),
+		service.WithAlerts(notifier),
+		service.WithLogger(logger),
+	}
+	var exported *metrics.Metrics
+	if metricsAddr != "" {
+		exported = metrics.New()
+		options = append(options, service.WithMetrics(exported))
+	}
+	scheduler := service.NewScheduler(options...)
+
+	if exported != nil {
+		exported.RegisterScheduler(scheduler)
+		server, err := exported.Serve(metricsAddr)
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+		logger.Info("Serving metrics", "url", "http://"+displayAddr(metricsAddr)+metrics.Path)
+
+		if err := exported.LoadHistory(store); err != nil {
+			logger.Warn("Failed to read last successes from history", logging.Err(err))
+		}
+	}
+
+	if httpAddr != "" {
+		access, err := dashboardAccess(dataDir, cfg)
+		if err != nil {
+			return err
+		}
+		dashboard := web.New(store, scheduler, validateJob, redactor, access)
+		server, err := dashboard.Serve(httpAddr)
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+		// The token stays out of the logs
+		logger.Info("Serving dashboard", "url", "http://"+web.ListenAddr(httpAddr)+"/")
+		if os.Getenv(daemon.EnvChild) == "" {
+			fmt.Printf("Open the dashboard at %s\n", dashboardURL(httpAddr, access.Token))
+		}
+	}
 
+	// Expose the control API to the CLI
+	server, err := control.Listen(dataDir, bus, scheduler, store)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	go server.Serve()
+
+	for _, job := range jobs {
+		scheduler.AddJob(job)
+		logger.Debug("Loaded job", logging.KeyJobID, job.ID, "method", job.Method, "url", redactor.URL(job.URL))
+	}
 	if len(jobs) == 0 {
-		fmt.Println("No jobs configured. Use 'tempo add' to create jobs first.")
-		fmt.Println("Starting scheduler in idle mode...")
+		logger.Info("No jobs configured, use 'tempo add' to create jobs")
 	} else {
-		fmt.Printf("Loading %d job(s)...\n", len(jobs))
-		for _, job := range jobs {
-			scheduler.AddJob(job)
-			fmt.Printf("  ✓ %s: %s %s\n", job.ID, job.Method, job.URL)
-		}
-		fmt.Println()
+		logger.Info("Loaded jobs", "count", len(jobs))
 	}
 
 	scheduler.Start()
 
-	if foreground {
-		fmt.Println("Scheduler running in foreground mode.")
-		fmt.Println("Press Ctrl+C to stop")
+	// Pick up 'tempo add'/'tempo remove' and manual edits without a restart
+	if jsonStore, ok := store.(*storage.JSONStorage); ok {
+		if err := scheduler.WatchFile(jsonStore.Path()); err != nil {
+			logger.Warn("Not watching job file for changes", "path", jsonStore.Path(), logging.Err(err))
+		}
+	}
 
-		// Wait for interrupt signal
-		stop := make(chan os.Signal, 1)
-		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-		<-stop
+	// Reload on SIGHUP, stop on interrupt signal or 'tempo stop'
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-		fmt.Println("\nStopping scheduler...")
-		scheduler.Stop()
-		fmt.Println("Scheduler stopped.")
-	} else {
-		fmt.Println("Scheduler started in background mode.")
-		fmt.Println("Use 'tempo logs' to view execution logs.")
+	running := true
+	for running {
+		select {
+		case <-reload:
+			scheduler.ReloadAndLog("SIGHUP")
+		case sig := <-stop:
+			logger.Info("Stopping scheduler", "signal", sig.String())
+			running = false
+		}
 	}
 
+	scheduler.Stop()
+	notifier.Wait()
+
 	return nil
 }
+
+// startDaemon spawns a detached scheduler and waits until it is serving
+func startDaemon(dataDir string) error {
+	if pid, running, err := daemon.ReadPID(dataDir); err != nil {
+		return err
+	} else if running {
+		return fmt.Errorf("scheduler is already running (pid %d)", pid)
+	}
+
+	// The background scheduler cannot ask for the secret key, so ask for it
+	// here and hand it over
+	secretKey, err := promptDaemonSecretKey(dataDir)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"start", "--foreground"}
+	if storageBackend != "" {
+		args = append(args, "--storage", storageBackend)
+	}
+	if metricsAddr != "" {
+		args = append(args, "--metrics", metricsAddr)
+	}
+	var access web.Access
+	if httpAddr != "" {
+		cfg, err := config.Load(dataDir)
+		if err != nil {
+			return err
+		}
+		if access, err = dashboardAccess(dataDir, cfg); err != nil {
+			return err
+		}
+		if err := access.Check(httpAddr); err != nil {
+			return err
+		}
+		args = append(args, "--http", httpAddr)
+	}
+
+	// The background scheduler has no terminal, log to a file
+	logPath := daemon.LogFilePath(dataDir)
+	if logFile != "" {
+		if logPath, err = filepath.Abs(logFile); err != nil {
+			return err
+		}
+	}
+	args = append(args,
+		"--log-file", logPath,
+		"--log-format", logFormat,
+		"--log-level", logLevel,
+		"--log-max-size", strconv.Itoa(logMaxSize),
+		"--log-max-files", strconv.Itoa(logMaxFiles),
+	)
+
+	pid, err := daemon.Spawn(dataDir, args, []byte(secretKey))
+	if err != nil {
+		return err
+	}
+
+	client := control.NewClient(dataDir)
+	deadline := time.Now().Add(daemonStartTimeout)
+	for time.Now().Before(deadline) {
+		if status, err := client.Status(); err == nil && status.PID == pid {
+			fmt.Printf("✓ Scheduler started in background (pid %d)\n", pid)
+			fmt.Printf("  Logs: %s\n", logPath)
+			if httpAddr != "" {
+				fmt.Printf("  Dashboard: %s\n", dashboardURL(httpAddr, access.Token))
+			}
+			fmt.Println("Use 'tempo status' to inspect it and 'tempo stop' to stop it.")
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("scheduler did not start within %v, see %s and %s", daemonStartTimeout, logPath, daemon.OutFilePath(dataDir))
+}
+
+// promptDaemonSecretKey asks for the secret key on a terminal when secrets
+// exist but no key is configured. It returns an empty key if none is needed.
+func promptDaemonSecretKey(dataDir string) (string, error) {
+	secretStore, err := secrets.Open(dataDir)
+	if err != nil {
+		return "", err
+	}
+	if !secretStore.Exists() || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", nil
+	}
+	if _, ok, err := secrets.LoadKey(dataDir); err != nil || ok {
+		return "", err
+	}
+
+	key, err := promptSecretKey(false)
+	if err != nil {
+		return "", err
+	}
+	if err := secretStore.Unlock(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// openLogger creates the scheduler's logger as set by the --log-* flags. The
+// returned function closes the log file, if any.
+func openLogger() (*slog.Logger, func() error, error) {
+	var out io.Writer = os.Stderr
+	closeLog := func() error { return nil }
+	if logFile != "" {
+		file, err := logging.OpenFile(logFile, int64(logMaxSize)<<20, logMaxFiles)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, closeLog = file, file.Close
+	}
+
+	logger, err := logging.New(out, logging.Options{Format: logFormat, Level: logLevel})
+	if err != nil {
+		closeLog()
+		return nil, nil, err
+	}
+	return logger, closeLog, nil
+}
+
+// dashboardAccess returns who may use the dashboard: holders of the token
+// set in the config from anywhere, or else holders of a generated token from
+// this machine only
+func dashboardAccess(dataDir string, cfg *config.Config) (web.Access, error) {
+	settings := cfg.Dashboard
+	if err := settings.Validate(); err != nil {
+		return web.Access{}, fmt.Errorf("invalid dashboard settings in %s: %v", config.FileName, err)
+	}
+	if settings == nil {
+		settings = &config.Dashboard{}
+	}
+
+	access := web.Access{Token: settings.Token, Hosts: settings.Hosts, Remote: settings.Token != ""}
+	if access.Token == "" {
+		token, err := web.LoadToken(dataDir)
+		if err != nil {
+			return web.Access{}, err
+		}
+		access.Token = token
+	}
+	return access, nil
+}
+
+// dashboardURL is the link to the dashboard. The token is passed in the
+// fragment, which browsers don't send to the server or in Referer headers.
+func dashboardURL(addr, token string) string {
+	return "http://" + web.ListenAddr(addr) + "/#token=" + url.QueryEscape(token)
+}
+
+// displayAddr turns a listen address such as ":9090" into one to connect to
+func displayAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("localhost", port)
+}
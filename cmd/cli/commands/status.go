@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"tempo/internal/control"
+	"tempo/internal/daemon"
+	"tempo/internal/storage"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of the running scheduler",
+	Long: `Show whether the scheduler is running, its uptime, how many jobs it has
+loaded, how busy its execution workers are and when each job fires next.
+
+Examples:
+  tempo status`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return err
+	}
+
+	status, err := control.NewClient(dataDir).Status()
+	if err != nil {
+		if pid, running, _ := daemon.ReadPID(dataDir); running {
+			return fmt.Errorf("scheduler (pid %d) is running but not responding: %v", pid, err)
+		}
+		fmt.Println("Scheduler is not running.")
+		fmt.Println("Use 'tempo start' to start it.")
+		return nil
+	}
+
+	fmt.Printf("Scheduler is running (pid %d)\n", status.PID)
+	fmt.Printf("  Started: %s\n", status.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Uptime: %s\n", time.Since(status.StartedAt).Round(time.Second))
+	fmt.Printf("  Jobs loaded: %d\n", len(status.Jobs))
+	if pool := status.Pool; pool != nil {
+		fmt.Printf("  Workers: %d/%d busy, %d/%d queued\n", pool.Busy, pool.Workers, pool.Queued, pool.QueueSize)
+		fmt.Printf("  Queue wait: avg %dms, max %dms\n", pool.WaitAvgMs, pool.WaitMaxMs)
+		fmt.Printf("  Executions: %d completed, %d skipped with a full queue\n", pool.Completed, pool.Rejected)
+	}
+
+	if len(status.Jobs) > 0 {
+		fmt.Println("\nNext runs:")
+		for _, job := range status.Jobs {
+			// Jobs paused without an end, and one-shot jobs that fired, have no next run
+			next := "-"
+			if !job.NextRun.IsZero() {
+				next = job.NextRun.Local().Format("2006-01-02 15:04:05")
+			} else if job.Paused {
+				next = "paused"
+			}
+			fmt.Printf("  %s: %s", job.Job.ID, next)
+			if !job.PrevRun.IsZero() {
+				fmt.Printf(" (last %s)", job.PrevRun.Local().Format("2006-01-02 15:04:05"))
+			}
+			if job.Paused && !job.NextRun.IsZero() {
+				fmt.Print(" [paused]")
+			}
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
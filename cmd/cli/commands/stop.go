@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"tempo/internal/daemon"
+	"tempo/internal/storage"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the background scheduler",
+	Long: `Gracefully stop the running scheduler. Executions in progress are allowed to finish.
+
+Examples:
+  tempo stop
+  tempo stop --timeout 30s`,
+	Args: cobra.NoArgs,
+	RunE: runStop,
+}
+
+var stopTimeout time.Duration
+
+func init() {
+	stopCmd.Flags().DurationVarP(&stopTimeout, "timeout", "t", 10*time.Second, "How long to wait for the scheduler to exit")
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return err
+	}
+
+	pid, running, err := daemon.ReadPID(dataDir)
+	if err != nil {
+		return err
+	}
+	if !running {
+		fmt.Println("Scheduler is not running.")
+		return nil
+	}
+
+	if err := daemon.Terminate(pid); err != nil {
+		return fmt.Errorf("failed to stop scheduler (pid %d): %v", pid, err)
+	}
+
+	fmt.Printf("Stopping scheduler (pid %d)...\n", pid)
+	deadline := time.Now().Add(stopTimeout)
+	for time.Now().Before(deadline) {
+		if _, running, _ := daemon.ReadPID(dataDir); !running {
+			fmt.Println("✓ Scheduler stopped")
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("scheduler (pid %d) did not stop within %v", pid, stopTimeout)
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"tempo/internal/config"
+	"tempo/internal/daemon"
+	"tempo/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Manage the storage backend",
+	Long: `Manage where tempo keeps jobs and execution history.
+
+Examples:
+  tempo storage migrate --to sqlite`,
+}
+
+var storageMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy jobs and history to another storage backend",
+	Long: `Copy all jobs and execution history from the current storage backend to another
+one and make it the default for future commands.
+
+Examples:
+  tempo storage migrate --to sqlite
+  tempo storage migrate --to json`,
+	Args: cobra.NoArgs,
+	RunE: runStorageMigrate,
+}
+
+var (
+	storageBackend string
+	migrateTo      string
+)
+
+func init() {
+	storageMigrateCmd.Flags().StringVar(&migrateTo, "to", "", "Target storage backend (json, sqlite)")
+	storageMigrateCmd.MarkFlagRequired("to")
+	storageCmd.AddCommand(storageMigrateCmd)
+}
+
+// openStorage opens the backend selected by --storage or the configuration
+func openStorage() (storage.Storage, error) {
+	return storage.Open("", storageBackend)
+}
+
+func runStorageMigrate(cmd *cobra.Command, args []string) error {
+	dataDir, err := storage.DataDir("")
+	if err != nil {
+		return err
+	}
+
+	// A running scheduler would keep writing history to the old backend
+	if pid, running, _ := daemon.ReadPID(dataDir); running {
+		return fmt.Errorf("scheduler is running (pid %d). Stop it with 'tempo stop' before migrating", pid)
+	}
+
+	cfg, err := config.Load(dataDir)
+	if err != nil {
+		return err
+	}
+
+	from := storageBackend
+	if from == "" {
+		from = cfg.Storage
+	}
+	if from == "" {
+		from = storage.BackendJSON
+	}
+	if from == migrateTo {
+		return fmt.Errorf("storage is already using the %s backend", migrateTo)
+	}
+
+	source, err := storage.Open(dataDir, from)
+	if err != nil {
+		return fmt.Errorf("failed to open %s storage: %v", from, err)
+	}
+	defer source.Close()
+
+	target, err := storage.Open(dataDir, migrateTo)
+	if err != nil {
+		return fmt.Errorf("failed to open %s storage: %v", migrateTo, err)
+	}
+	defer target.Close()
+
+	result, err := storage.Migrate(source, target)
+	if err != nil {
+		return err
+	}
+
+	cfg.Storage = migrateTo
+	if err := cfg.Save(dataDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Migrated %d jobs and %d executions from %s to %s\n", result.Jobs, result.Executions, from, migrateTo)
+	fmt.Printf("  %s is now the default storage backend\n", migrateTo)
+	return nil
+}
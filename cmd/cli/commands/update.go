@@ -0,0 +1,303 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update [job-id]",
+	Short: "Change fields of an existing job",
+	Long: `Change individual fields of an existing job. Only the given flags are applied,
+everything else is kept. Use 'tempo edit' to change assertions or anything
+else without a flag.
+
+Examples:
+  tempo update health-check --schedule "0 * * * * *"
+  tempo update weekly-report --timezone America/New_York
+  tempo update announcement --at "2026-11-02 09:00"
+  tempo update sync --header 'API-Key={{ secret "new_key" }}' --remove-header X-Debug
+  tempo update sync --retries 3 --retry-delay 5s
+  tempo update sync --tag warehouse --remove-tag legacy
+  tempo update report --overlap queue --queue-limit 3
+  tempo update internal-sync --timeout 30s --proxy ""
+  tempo update billing --alert-slack "https://hooks.slack.com/services/..." --alert-repeat 6h
+  tempo update billing --alert-channel ""`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUpdate,
+}
+
+var (
+	updateURL          string
+	updateMethod       string
+	updateSchedule     string
+	updateTimeZone     string
+	updateAt           string
+	updateEvery        time.Duration
+	updateAnchor       string
+	updateBody         string
+	updateHeaders      []string
+	updateRemoveHeader []string
+	updateTags         []string
+	updateRemoveTags   []string
+	updateOverlap      string
+	updateQueueLimit   int
+	updateHTTP         httpFlags
+	updateAlerts       alertFlags
+
+	updateRetryAttempts   int
+	updateRetryDelay      time.Duration
+	updateRetryMultiplier float64
+	updateRetryMaxDelay   time.Duration
+	updateRetryJitter     float64
+	updateRetryOn         []string
+)
+
+func init() {
+	updateCmd.Flags().StringVarP(&updateURL, "url", "u", "", "Webhook URL")
+	updateCmd.Flags().StringVarP(&updateMethod, "method", "m", "", "HTTP method (GET, POST, PUT, DELETE)")
+	updateCmd.Flags().StringVarP(&updateSchedule, "schedule", "s", "", "Cron schedule expression (e.g., '*/30 * * * * *')")
+	updateCmd.Flags().StringVar(&updateTimeZone, "timezone", "", "IANA time zone the schedule is evaluated in, '' for the scheduler's local time zone")
+	updateCmd.Flags().StringVar(&updateAt, "at", "", "Run once at a time like '2025-01-31 09:00' or after a duration like '2h', replacing the schedule")
+	updateCmd.Flags().DurationVar(&updateEvery, "every", 0, "Run at a fixed interval like '90m', replacing the schedule")
+	updateCmd.Flags().StringVar(&updateAnchor, "anchor", "", "Time the interval counts from (default: now)")
+	updateCmd.Flags().StringVarP(&updateBody, "body", "b", "", "Request body, '' to remove it")
+	updateCmd.Flags().StringArrayVarP(&updateHeaders, "header", "H", []string{}, "Add or replace an HTTP header (format: 'Key=Value')")
+	updateCmd.Flags().StringArrayVar(&updateRemoveHeader, "remove-header", []string{}, "Remove an HTTP header by name")
+	updateCmd.Flags().StringSliceVarP(&updateTags, "tag", "t", []string{}, "Add tags")
+	updateCmd.Flags().StringSliceVar(&updateRemoveTags, "remove-tag", []string{}, "Remove tags")
+	updateCmd.Flags().StringVar(&updateOverlap, "overlap", "", "What to do when a run is due while the previous one is running: allow, skip, queue or cancel")
+	updateCmd.Flags().IntVar(&updateQueueLimit, "queue-limit", 0, "Runs that may wait for the previous one with --overlap queue, 0 for the default")
+	updateHTTP.register(updateCmd)
+	updateAlerts.register(updateCmd)
+
+	updateCmd.Flags().IntVar(&updateRetryAttempts, "retries", 1, "Maximum attempts per run, including the first one (1 disables retries)")
+	updateCmd.Flags().DurationVar(&updateRetryDelay, "retry-delay", time.Second, "Delay before the first retry")
+	updateCmd.Flags().Float64Var(&updateRetryMultiplier, "retry-multiplier", 2, "Backoff multiplier applied to the delay after every retry")
+	updateCmd.Flags().DurationVar(&updateRetryMaxDelay, "retry-max-delay", time.Minute, "Maximum delay between retries")
+	updateCmd.Flags().Float64Var(&updateRetryJitter, "retry-jitter", 0.2, "Fraction of each delay that is randomized (0-1)")
+	updateCmd.Flags().StringSliceVar(&updateRetryOn, "retry-on", []string{}, "Retryable conditions: network, timeout, status codes or classes")
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	jobID := args[0]
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	// The flags are applied to the stored job under the storage lock, so
+	// changes saved by another process in the meantime are kept
+	var job, updated types.Job
+	err = store.UpdateJob(jobID, func(current types.Job, exists bool) (types.Job, error) {
+		if !exists {
+			return types.Job{}, fmt.Errorf("job '%s' not found", jobID)
+		}
+		job = current
+
+		var err error
+		updated, err = applyUpdateFlags(cmd, current)
+		if err != nil {
+			return types.Job{}, err
+		}
+		if err := validateJob(updated); err != nil {
+			return types.Job{}, err
+		}
+		if sameJob(job, updated) {
+			return types.Job{}, storage.ErrJobUnchanged
+		}
+		return updated, nil
+	})
+	if err != nil {
+		return err
+	}
+	if sameJob(job, updated) {
+		fmt.Printf("No changes to job '%s'\n", jobID)
+		return nil
+	}
+
+	fmt.Printf("✓ Updated job '%s'\n", jobID)
+	if err := printJobDiff(job, updated); err != nil {
+		return err
+	}
+
+	reloadScheduler()
+	return nil
+}
+
+// applyUpdateFlags returns a copy of the job with the flags given on the command line applied
+func applyUpdateFlags(cmd *cobra.Command, job types.Job) (types.Job, error) {
+	flags := cmd.Flags()
+
+	if flags.Changed("url") {
+		job.URL = updateURL
+	}
+	if flags.Changed("method") {
+		job.Method = strings.ToUpper(updateMethod)
+	}
+	if flags.Changed("timezone") {
+		job.TimeZone = updateTimeZone
+	}
+	if err := applyScheduleUpdate(cmd, &job); err != nil {
+		return job, err
+	}
+	if flags.Changed("body") {
+		job.Body = updateBody
+	}
+
+	if len(updateHeaders) > 0 || len(updateRemoveHeader) > 0 {
+		headers := make(map[string]string, len(job.Headers))
+		for name, value := range job.Headers {
+			headers[name] = value
+		}
+		for _, name := range updateRemoveHeader {
+			if !removeHeader(headers, name) {
+				return job, fmt.Errorf("job '%s' has no header %s", job.ID, name)
+			}
+		}
+		for _, header := range updateHeaders {
+			name, value, found := strings.Cut(header, "=")
+			if !found {
+				return job, fmt.Errorf("invalid header format: %s. Use 'Key=Value'", header)
+			}
+			// Replace the header even if it was added with different casing
+			removeHeader(headers, name)
+			headers[name] = value
+		}
+		job.Headers = headers
+	}
+
+	if len(updateTags) > 0 || len(updateRemoveTags) > 0 {
+		var tags []string
+		for _, tag := range job.Tags {
+			if !slices.Contains(updateRemoveTags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		for _, tag := range updateTags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		job.Tags = tags
+	}
+
+	if flags.Changed("overlap") {
+		job.Overlap = updateOverlap
+		if job.OverlapPolicy() != types.OverlapQueue && !flags.Changed("queue-limit") {
+			// The limit only applies to queued runs
+			job.QueueLimit = 0
+		}
+	}
+	if flags.Changed("queue-limit") {
+		job.QueueLimit = updateQueueLimit
+	}
+
+	if updateHTTP.changed(cmd) {
+		settings, err := updateHTTP.apply(cmd, job.HTTP)
+		if err != nil {
+			return job, err
+		}
+		job.HTTP = settings
+	}
+	if updateAlerts.changed(cmd) {
+		job.Alert = updateAlerts.apply(cmd, job.Alert)
+	}
+
+	if err := applyRetryFlags(cmd, &job); err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+// applyScheduleUpdate replaces the job's schedule if --schedule, --at, --every
+// or --anchor is given. A one-shot job that already ran is scheduled again.
+func applyScheduleUpdate(cmd *cobra.Command, job *types.Job) error {
+	flags := cmd.Flags()
+	if !flags.Changed("schedule") && !flags.Changed("at") && !flags.Changed("every") && !flags.Changed("anchor") {
+		return nil
+	}
+
+	// --anchor alone moves the current interval
+	every := updateEvery
+	if !flags.Changed("every") && job.ScheduleKind() == types.ScheduleEvery {
+		every = time.Duration(job.Every)
+	}
+
+	job.CronExpr = updateSchedule
+	job.At, job.Every, job.Anchor, job.CompletedAt = nil, 0, nil, nil
+	return setScheduleFlags(job, updateAt, every, updateAnchor)
+}
+
+// applyRetryFlags changes the given fields of the job's retry policy, starting
+// from the defaults of 'tempo add' if the job has none. --retries 1 removes it.
+func applyRetryFlags(cmd *cobra.Command, job *types.Job) error {
+	flags := cmd.Flags()
+	if !flags.Changed("retries") && !flags.Changed("retry-delay") && !flags.Changed("retry-multiplier") &&
+		!flags.Changed("retry-max-delay") && !flags.Changed("retry-jitter") && !flags.Changed("retry-on") {
+		return nil
+	}
+
+	var policy types.RetryPolicy
+	if job.Retry != nil {
+		policy = *job.Retry
+		policy.RetryOn = slices.Clone(job.Retry.RetryOn)
+	} else {
+		policy = types.RetryPolicy{
+			MaxAttempts:  1,
+			InitialDelay: types.Duration(time.Second),
+			Multiplier:   2,
+			MaxDelay:     types.Duration(time.Minute),
+			Jitter:       0.2,
+		}
+	}
+
+	if flags.Changed("retries") {
+		policy.MaxAttempts = updateRetryAttempts
+	}
+	if flags.Changed("retry-delay") {
+		policy.InitialDelay = types.Duration(updateRetryDelay)
+	}
+	if flags.Changed("retry-multiplier") {
+		policy.Multiplier = updateRetryMultiplier
+	}
+	if flags.Changed("retry-max-delay") {
+		policy.MaxDelay = types.Duration(updateRetryMaxDelay)
+	}
+	if flags.Changed("retry-jitter") {
+		policy.Jitter = updateRetryJitter
+	}
+	if flags.Changed("retry-on") {
+		policy.RetryOn = updateRetryOn
+	}
+
+	if policy.MaxAttempts <= 1 {
+		if flags.Changed("retries") {
+			job.Retry = nil
+			return nil
+		}
+		return fmt.Errorf("job '%s' does not retry. Set --retries above 1 as well", job.ID)
+	}
+
+	job.Retry = &policy
+	return nil
+}
+
+// removeHeader deletes a header by case-insensitive name and reports whether it was present
+func removeHeader(headers map[string]string, name string) bool {
+	for existing := range headers {
+		if strings.EqualFold(existing, name) {
+			delete(headers, existing)
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,9 @@ import (
 	"os"
 	"tempo/cmd/cli/commands"
 
+	// Embed the time zone database so job time zones work on hosts without one
+	_ "time/tzdata"
+
 	"github.com/spf13/cobra"
 )
 
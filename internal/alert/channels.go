@@ -0,0 +1,199 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"tempo/internal/templating"
+	"tempo/internal/types"
+	"text/template"
+	"time"
+)
+
+// sendTimeout bounds the delivery of an alert to a single channel
+const sendTimeout = 10 * time.Second
+
+var client = &http.Client{Timeout: sendTimeout}
+
+// Send delivers the alert to the channel. Secret references in its settings
+// must have been resolved with Resolve.
+func Send(channel types.AlertChannel, alert types.Alert) error {
+	switch channel.Type {
+	case types.ChannelWebhook:
+		return post(channel.URL, channel.Headers, alert)
+	case types.ChannelSlack:
+		return post(channel.URL, nil, slackMessage(alert))
+	case types.ChannelEmail:
+		return sendEmail(channel, alert)
+	default:
+		return fmt.Errorf("invalid alert channel type %q", channel.Type)
+	}
+}
+
+// SecretResolver looks up the secrets referenced by channel settings
+type SecretResolver interface {
+	Secret(name string) (string, error)
+}
+
+// Resolve returns the channel with the templates in its URL, headers and
+// password expanded, so {{ secret "name" }} keeps webhook tokens and SMTP
+// passwords out of the job and configuration files. It also returns the
+// secret values used, even when expanding fails, so they can be redacted
+// from errors. Without a resolver secret references fail.
+func Resolve(channel types.AlertChannel, secrets SecretResolver) (types.AlertChannel, []string, error) {
+	var used []string
+	funcs := template.FuncMap{}
+	if secrets != nil {
+		funcs["secret"] = func(name string) (string, error) {
+			value, err := secrets.Secret(name)
+			if err == nil {
+				used = append(used, value)
+			}
+			return value, err
+		}
+	}
+
+	resolved := channel
+	var err error
+	if resolved.URL, err = templating.Expand("url", channel.URL, funcs); err != nil {
+		return channel, used, err
+	}
+	if resolved.Password, err = templating.Expand("password", channel.Password, funcs); err != nil {
+		return channel, used, err
+	}
+	if len(channel.Headers) > 0 {
+		resolved.Headers = make(map[string]string, len(channel.Headers))
+		for name, value := range channel.Headers {
+			if resolved.Headers[name], err = templating.Expand("header "+name, value, funcs); err != nil {
+				return channel, used, err
+			}
+		}
+	}
+	return resolved, used, nil
+}
+
+// ValidateTemplates parses the templates in the channel's settings
+func ValidateTemplates(channel types.AlertChannel) error {
+	if err := templating.ValidateText("url", channel.URL, nil); err != nil {
+		return err
+	}
+	if err := templating.ValidateText("password", channel.Password, nil); err != nil {
+		return err
+	}
+	for name, value := range channel.Headers {
+		if err := templating.ValidateText("header "+name, value, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends the payload as JSON and fails on status codes of 400 and above
+func post(url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tempo")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("channel returned %s", resp.Status)
+	}
+	return nil
+}
+
+// slackPayload is the message of a Slack incoming webhook. Mattermost,
+// Rocket.Chat and others accept it as well.
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// slackMessage formats the alert with the subject in bold
+func slackMessage(alert types.Alert) slackPayload {
+	icon := ":rotating_light:"
+	switch alert.Kind {
+	case types.AlertRecovered:
+		icon = ":white_check_mark:"
+	case types.AlertTest:
+		icon = ":bell:"
+	}
+
+	subject, details, _ := strings.Cut(alert.Summary(), "\n")
+	text := fmt.Sprintf("%s *%s*", icon, subject)
+	if details != "" {
+		text += "\n" + details
+	}
+	return slackPayload{Text: text}
+}
+
+// sendEmail sends the alert as a plain text email. The connection is
+// upgraded with STARTTLS when the server offers it.
+func sendEmail(channel types.AlertChannel, alert types.Alert) error {
+	var auth smtp.Auth
+	if channel.Username != "" {
+		host, _, err := net.SplitHostPort(channel.SMTPServer)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP server: %v", err)
+		}
+		auth = smtp.PlainAuth("", channel.Username, channel.Password, host)
+	}
+
+	// The envelope takes bare addresses, the headers keep display names
+	from, err := mail.ParseAddress(channel.From)
+	if err != nil {
+		return fmt.Errorf("invalid email sender: %v", err)
+	}
+	var to []string
+	for _, recipient := range channel.To {
+		addr, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid email recipient: %v", err)
+		}
+		to = append(to, addr.Address)
+	}
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(alert.Subject())
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", channel.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(channel.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [tempo] %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.Time.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(alert.Summary(), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	// net/smtp has no timeout of its own
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(channel.SMTPServer, auth, from.Address, to, msg.Bytes())
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(sendTimeout):
+		return fmt.Errorf("timed out sending email via %s", channel.SMTPServer)
+	}
+}
@@ -0,0 +1,101 @@
+package alert
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"tempo/internal/templating"
+	"tempo/internal/types"
+	"testing"
+	"time"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Secret(name string) (string, error) {
+	value, ok := s[name]
+	if !ok {
+		return "", errors.New("secret " + name + " not found")
+	}
+	return value, nil
+}
+
+func TestResolve(t *testing.T) {
+	secrets := staticSecrets{"hook": "T000/B000/XXXX", "token": "xyz", "smtp": "hunter2"}
+	channel := types.AlertChannel{
+		Type:     types.ChannelWebhook,
+		URL:      `https://hooks.example.com/services/{{ secret "hook" }}`,
+		Headers:  map[string]string{"Authorization": `Bearer {{ secret "token" }}`, "X-Source": "tempo"},
+		Password: `{{ secret "smtp" }}`,
+	}
+
+	resolved, used, err := Resolve(channel, secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.URL != "https://hooks.example.com/services/T000/B000/XXXX" {
+		t.Errorf("URL = %q", resolved.URL)
+	}
+	if resolved.Headers["Authorization"] != "Bearer xyz" || resolved.Headers["X-Source"] != "tempo" {
+		t.Errorf("Headers = %v", resolved.Headers)
+	}
+	if resolved.Password != "hunter2" {
+		t.Errorf("Password = %q", resolved.Password)
+	}
+	slices.Sort(used)
+	if want := []string{"T000/B000/XXXX", "hunter2", "xyz"}; !slices.Equal(used, want) {
+		t.Errorf("used = %v, want %v", used, want)
+	}
+	if channel.Headers["Authorization"] != `Bearer {{ secret "token" }}` {
+		t.Error("Resolve() changed the headers of the original channel")
+	}
+
+	if _, _, err := Resolve(channel, nil); err == nil || !strings.Contains(err.Error(), templating.ErrNoSecrets.Error()) {
+		t.Errorf("Resolve() without a resolver = %v, want %v", err, templating.ErrNoSecrets)
+	}
+	if _, _, err := Resolve(types.AlertChannel{URL: `{{ secret "missing" }}`}, secrets); err == nil {
+		t.Error("Resolve() of a missing secret succeeded")
+	}
+}
+
+func TestNotifierSendsWithSecrets(t *testing.T) {
+	const token = "s3cr3t-token"
+	paths := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+		if strings.HasSuffix(r.URL.Path, "/down") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	global := &types.AlertPolicy{Channels: []types.AlertChannel{
+		{Name: "ok", Type: types.ChannelWebhook, URL: server.URL + `/hooks/{{ secret "token" }}`},
+		{Name: "down", Type: types.ChannelWebhook, URL: server.URL + `/hooks/{{ secret "token" }}/down`},
+	}}
+	n, err := New(t.TempDir(), global, nil, staticSecrets{"token": token}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.Observe(types.Job{ID: "a", URL: "https://example.com/"}, types.Execution{Status: types.StatusFailure, FinishedAt: time.Now()})
+	n.Wait()
+
+	got := []string{<-paths, <-paths}
+	slices.Sort(got)
+	if want := []string{"/hooks/" + token, "/hooks/" + token + "/down"}; !slices.Equal(got, want) {
+		t.Errorf("channels were called at %v, want %v", got, want)
+	}
+	if !strings.Contains(logs.String(), "Sent alert") || !strings.Contains(logs.String(), "Failed to send alert") {
+		t.Errorf("notifier did not log to its logger:\n%s", logs.String())
+	}
+	if strings.Contains(logs.String(), token) {
+		t.Errorf("logs leak the secret:\n%s", logs.String())
+	}
+}
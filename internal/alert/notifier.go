@@ -0,0 +1,195 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"tempo/internal/logging"
+	"tempo/internal/redact"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"time"
+)
+
+// StateFileName is the file inside the data directory that keeps open
+// incidents across scheduler restarts
+const StateFileName = "alerts.json"
+
+// Notifier turns the outcomes of job runs into alerts. A job that keeps
+// failing raises one alert when it reaches its policy's threshold, optional
+// reminders, and one alert when it recovers, instead of one per failed run.
+type Notifier struct {
+	global    *types.AlertPolicy
+	redact    *redact.Redactor
+	secrets   SecretResolver
+	log       *slog.Logger
+	statePath string
+
+	mutex     sync.Mutex
+	streaks   map[string]*incident // failing jobs below their alert threshold
+	incidents map[string]*incident // failing jobs that raised an alert
+	sending   sync.WaitGroup
+}
+
+// incident tracks the consecutive failed runs of a job
+type incident struct {
+	FailingSince time.Time `json:"failing_since"`
+	Failures     int       `json:"failures"`
+	NotifiedAt   time.Time `json:"notified_at"` // when the last alert about it was sent, zero below the threshold
+}
+
+// New creates a notifier applying the global policy to jobs without their
+// own. Secrets referenced by channels are looked up with the resolver, and
+// logs go to the logger, or to slog's default one if it is nil. Incidents
+// open when the scheduler last stopped are read from the data directory, so
+// a restart neither repeats their alerts nor misses their recovery.
+func New(dataDir string, global *types.AlertPolicy, redactor *redact.Redactor, secrets SecretResolver, logger *slog.Logger) (*Notifier, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	n := &Notifier{
+		global:    global,
+		redact:    redactor,
+		secrets:   secrets,
+		log:       logger,
+		statePath: filepath.Join(dataDir, StateFileName),
+		streaks:   make(map[string]*incident),
+		incidents: make(map[string]*incident),
+	}
+
+	data, err := os.ReadFile(n.statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read alert state: %v", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &n.incidents); err != nil {
+			return nil, fmt.Errorf("failed to parse alert state: %v", err)
+		}
+	}
+	return n, nil
+}
+
+// Observe records the outcome of a run, given as its last attempt, and
+// sends the alerts it causes. Skipped and cancelled runs are ignored.
+func (n *Notifier) Observe(job types.Job, exec types.Execution) {
+	if exec.Status != types.StatusSuccess && exec.Status != types.StatusFailure {
+		return
+	}
+
+	policy, err := job.Alert.WithDefaults(n.global)
+	if err != nil {
+		n.log.Warn("Invalid alert policy", logging.KeyJobID, job.ID, logging.Err(err))
+	}
+	if policy.Disabled || len(policy.Channels) == 0 {
+		n.forget(job.ID)
+		return
+	}
+
+	alert, ok := n.update(job.ID, exec, policy)
+	if !ok {
+		return
+	}
+	alert.URL = n.redact.URL(job.URL)
+	alert.Execution = &exec
+	n.send(policy.Channels, alert)
+}
+
+// update counts the run and decides whether it causes an alert
+func (n *Notifier) update(jobID string, exec types.Execution, policy types.AlertPolicy) (types.Alert, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	now := time.Now()
+	open := n.incidents[jobID]
+
+	if exec.Status == types.StatusSuccess {
+		delete(n.streaks, jobID)
+		if open == nil {
+			return types.Alert{}, false
+		}
+		delete(n.incidents, jobID)
+		n.saveLocked()
+		if !policy.AlertOnRecovery() {
+			return types.Alert{}, false
+		}
+		return types.Alert{Kind: types.AlertRecovered, JobID: jobID, Time: now, Failures: open.Failures, FailingSince: open.FailingSince}, true
+	}
+
+	if open != nil {
+		open.Failures++
+		if policy.Repeat == 0 || now.Sub(open.NotifiedAt) < time.Duration(policy.Repeat) {
+			return types.Alert{}, false
+		}
+		open.NotifiedAt = now
+		n.saveLocked()
+		return types.Alert{Kind: types.AlertStillFailing, JobID: jobID, Time: now, Failures: open.Failures, FailingSince: open.FailingSince}, true
+	}
+
+	streak := n.streaks[jobID]
+	if streak == nil {
+		streak = &incident{FailingSince: exec.FinishedAt}
+		n.streaks[jobID] = streak
+	}
+	streak.Failures++
+	if streak.Failures < policy.Threshold() {
+		return types.Alert{}, false
+	}
+
+	delete(n.streaks, jobID)
+	streak.NotifiedAt = now
+	n.incidents[jobID] = streak
+	n.saveLocked()
+	return types.Alert{Kind: types.AlertFailing, JobID: jobID, Time: now, Failures: streak.Failures, FailingSince: streak.FailingSince}, true
+}
+
+// send delivers the alert to every channel in the background
+func (n *Notifier) send(channels []types.AlertChannel, alert types.Alert) {
+	for _, channel := range channels {
+		n.sending.Add(1)
+		go func() {
+			defer n.sending.Done()
+			resolved, used, err := Resolve(channel, n.secrets)
+			if err == nil {
+				err = Send(resolved, alert)
+			}
+			if err != nil {
+				n.log.Warn("Failed to send alert", logging.KeyJobID, alert.JobID, "kind", alert.Kind, "channel", channel.String(), logging.Err(n.redact.WithValues(used...).Error(err)))
+				return
+			}
+			n.log.Info("Sent alert", logging.KeyJobID, alert.JobID, "kind", alert.Kind, "channel", channel.String())
+		}()
+	}
+}
+
+// Wait blocks until the alerts being sent are delivered or have failed
+func (n *Notifier) Wait() {
+	n.sending.Wait()
+}
+
+// forget drops the state of a job that no longer alerts
+func (n *Notifier) forget(jobID string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	delete(n.streaks, jobID)
+	if _, ok := n.incidents[jobID]; ok {
+		delete(n.incidents, jobID)
+		n.saveLocked()
+	}
+}
+
+// saveLocked writes the open incidents to the state file
+// The caller must hold n.mutex
+func (n *Notifier) saveLocked() {
+	data, err := json.MarshalIndent(n.incidents, "", "  ")
+	if err != nil {
+		n.log.Error("Failed to save alert state", logging.Err(err))
+		return
+	}
+	if err := storage.WriteFileAtomic(n.statePath, data, 0600); err != nil {
+		n.log.Error("Failed to save alert state", logging.Err(err))
+	}
+}
@@ -0,0 +1,280 @@
+package assertion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"tempo/internal/types"
+	"time"
+)
+
+// MaxBodySize is how much of a response body is kept for body, JSON and
+// schema assertions. Larger bodies fail those checks.
+const MaxBodySize = 1 << 20
+
+// maxActualLength bounds how much of a body is quoted in a failure
+const maxActualLength = 200
+
+// Response is what assertions are checked against
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Truncated  bool // the body was longer than MaxBodySize
+	Latency    time.Duration
+}
+
+// Error is returned for a response that failed one or more assertions
+type Error struct {
+	Failures []types.AssertionFailure
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		parts[i] = fmt.Sprintf("%s: expected %s, got %s", failure.Check, failure.Expected, failure.Actual)
+	}
+	noun := "assertion"
+	if len(e.Failures) > 1 {
+		noun = "assertions"
+	}
+	return fmt.Sprintf("%d %s failed: %s", len(e.Failures), noun, strings.Join(parts, "; "))
+}
+
+// ChecksStatus reports whether the assertions replace the default status < 400 check
+func ChecksStatus(a *types.Assertions) bool {
+	return a != nil && len(a.Status) > 0
+}
+
+// NeedsBody reports whether checking the assertions requires the response body
+func NeedsBody(a *types.Assertions) bool {
+	return a != nil && (len(a.BodyContains) > 0 || len(a.BodyMatches) > 0 || len(a.JSON) > 0 || a.JSONSchema != nil)
+}
+
+// Validate checks that every assertion is well formed
+func Validate(a *types.Assertions) error {
+	if a == nil {
+		return nil
+	}
+
+	for _, spec := range a.Status {
+		if _, _, err := parseStatus(spec); err != nil {
+			return err
+		}
+	}
+	if a.MaxLatency < 0 {
+		return fmt.Errorf("max latency cannot be negative")
+	}
+	for name, pattern := range a.Headers {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern for header %s: %v", name, err)
+		}
+	}
+	for _, pattern := range a.BodyMatches {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid body pattern: %v", err)
+		}
+	}
+	for _, check := range a.JSON {
+		if _, err := parsePath(check.Path); err != nil {
+			return err
+		}
+		if check.Equals != nil && check.Exists != nil && !*check.Exists {
+			return fmt.Errorf("JSON assertion %s cannot both equal a value and not exist", check.Path)
+		}
+	}
+	if a.JSONSchema != nil {
+		if _, err := compileSchema(a.JSONSchema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check returns every assertion the response fails
+func Check(a *types.Assertions, resp Response) []types.AssertionFailure {
+	if a == nil {
+		return nil
+	}
+
+	var failures []types.AssertionFailure
+	fail := func(check, expected, actual string) {
+		failures = append(failures, types.AssertionFailure{Check: check, Expected: expected, Actual: actual})
+	}
+
+	if len(a.Status) > 0 && !matchesStatus(a.Status, resp.StatusCode) {
+		fail("status", strings.Join(a.Status, " or "), strconv.Itoa(resp.StatusCode))
+	}
+
+	if a.MaxLatency > 0 && resp.Latency > time.Duration(a.MaxLatency) {
+		fail("latency", "at most "+a.MaxLatency.String(), resp.Latency.Round(time.Millisecond).String())
+	}
+
+	for name, pattern := range a.Headers {
+		values, present := resp.Header[http.CanonicalHeaderKey(name)]
+		value := strings.Join(values, ", ")
+		switch {
+		case !present:
+			fail("header "+name, fmt.Sprintf("matching %q", pattern), "missing")
+		case !regexp.MustCompile(pattern).MatchString(value):
+			fail("header "+name, fmt.Sprintf("matching %q", pattern), strconv.Quote(value))
+		}
+	}
+
+	if !NeedsBody(a) {
+		return failures
+	}
+	if resp.Truncated {
+		fail("body", fmt.Sprintf("at most %d bytes", MaxBodySize), "larger body")
+		return failures
+	}
+
+	body := string(resp.Body)
+	for _, substring := range a.BodyContains {
+		if !strings.Contains(body, substring) {
+			fail("body", fmt.Sprintf("containing %q", substring), quoteBody(body))
+		}
+	}
+	for _, pattern := range a.BodyMatches {
+		if !regexp.MustCompile(pattern).MatchString(body) {
+			fail("body", fmt.Sprintf("matching %q", pattern), quoteBody(body))
+		}
+	}
+
+	if len(a.JSON) == 0 && a.JSONSchema == nil {
+		return failures
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(resp.Body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		fail("json", "a JSON body", quoteBody(body))
+		return failures
+	}
+
+	for _, check := range a.JSON {
+		checkJSON(check, doc, fail)
+	}
+
+	if a.JSONSchema != nil {
+		if schema, err := compileSchema(a.JSONSchema); err != nil {
+			fail("json schema", "a valid schema", err.Error())
+		} else if err := schema.Validate(doc); err != nil {
+			fail("json schema", "body matching the schema", strings.ReplaceAll(err.Error(), "\n", " "))
+		}
+	}
+
+	return failures
+}
+
+func checkJSON(check types.JSONAssertion, doc interface{}, fail func(check, expected, actual string)) {
+	name := "json " + check.Path
+
+	steps, err := parsePath(check.Path)
+	if err != nil {
+		fail(name, "a valid JSONPath", err.Error())
+		return
+	}
+	value, exists := lookupPath(doc, steps)
+
+	if check.Exists != nil && !*check.Exists {
+		if exists {
+			fail(name, "no value", formatJSON(value))
+		}
+		return
+	}
+	if !exists {
+		expected := "a value"
+		if check.Equals != nil {
+			expected = formatJSON(check.Equals)
+		}
+		fail(name, expected, "missing")
+		return
+	}
+	if check.Equals != nil && !equalJSON(check.Equals, value) {
+		fail(name, formatJSON(check.Equals), formatJSON(value))
+	}
+}
+
+// parseStatus parses "200", "2xx" or "200-299" into an inclusive range
+func parseStatus(spec string) (int, int, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	invalid := fmt.Errorf("invalid status %q. Use a code like '200', a class like '2xx' or a range like '200-299'", spec)
+
+	if len(spec) == 3 && strings.HasSuffix(spec, "xx") && spec[0] >= '1' && spec[0] <= '5' {
+		class := int(spec[0]-'0') * 100
+		return class, class + 99, nil
+	}
+
+	low, high, isRange := strings.Cut(spec, "-")
+	from, err := strconv.Atoi(low)
+	if err != nil || from < 100 || from > 599 {
+		return 0, 0, invalid
+	}
+	if !isRange {
+		return from, from, nil
+	}
+
+	to, err := strconv.Atoi(high)
+	if err != nil || to < from || to > 599 {
+		return 0, 0, invalid
+	}
+	return from, to, nil
+}
+
+func matchesStatus(specs []string, statusCode int) bool {
+	for _, spec := range specs {
+		from, to, err := parseStatus(spec)
+		if err == nil && statusCode >= from && statusCode <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// equalJSON compares an expected value from the job with a decoded JSON
+// value, normalizing both through JSON so numbers compare by value
+func equalJSON(expected, actual interface{}) bool {
+	return reflect.DeepEqual(normalizeJSON(expected), normalizeJSON(actual))
+}
+
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+func formatJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return truncate(string(data))
+}
+
+func quoteBody(body string) string {
+	if body == "" {
+		return "an empty body"
+	}
+	return strconv.Quote(truncate(body))
+}
+
+func truncate(s string) string {
+	if len(s) <= maxActualLength {
+		return s
+	}
+	return s[:maxActualLength] + "..."
+}
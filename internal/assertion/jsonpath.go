@@ -0,0 +1,102 @@
+package assertion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a single member name or array index of a JSONPath
+type pathStep struct {
+	name  string
+	index int
+	isKey bool
+}
+
+// parsePath parses the JSONPath subset supported by assertions: a leading
+// "$" followed by ".name", "['name']" and "[index]" steps. Negative indices
+// count from the end of an array.
+func parsePath(path string) ([]pathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with '$'", path)
+	}
+
+	var steps []pathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty member name", path)
+			}
+			steps = append(steps, pathStep{name: rest[:end], isKey: true})
+			rest = rest[end:]
+
+		case '[':
+			// Quoted names may contain ']' and '.', so look for the closing quote
+			if len(rest) > 1 && (rest[1] == '\'' || rest[1] == '"') {
+				end := strings.Index(rest[2:], string(rest[1])+"]")
+				if end < 0 {
+					return nil, fmt.Errorf("invalid JSONPath %q: missing closing quote or ']'", path)
+				}
+				steps = append(steps, pathStep{name: rest[2 : 2+end], isKey: true})
+				rest = rest[2+end+2:]
+				continue
+			}
+
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing ']'", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: %q is not an index or quoted name", path, inner)
+			}
+			steps = append(steps, pathStep{index: index})
+
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", path, rest[0])
+		}
+	}
+
+	return steps, nil
+}
+
+// lookupPath returns the value at the path in a decoded JSON document
+func lookupPath(doc interface{}, steps []pathStep) (interface{}, bool) {
+	value := doc
+	for _, step := range steps {
+		if step.isKey {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = object[step.name]; !ok {
+				return nil, false
+			}
+			continue
+		}
+
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		index := step.index
+		if index < 0 {
+			index += len(array)
+		}
+		if index < 0 || index >= len(array) {
+			return nil, false
+		}
+		value = array[index]
+	}
+	return value, true
+}
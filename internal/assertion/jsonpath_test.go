@@ -0,0 +1,137 @@
+package assertion
+
+import (
+	"encoding/json"
+	"reflect"
+	"tempo/internal/types"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []pathStep
+		wantErr bool
+	}{
+		{path: "$", want: nil},
+		{path: "$.status", want: []pathStep{{name: "status", isKey: true}}},
+		{path: "$.data.items[0].id", want: []pathStep{
+			{name: "data", isKey: true}, {name: "items", isKey: true}, {index: 0}, {name: "id", isKey: true},
+		}},
+		{path: "$[-1]", want: []pathStep{{index: -1}}},
+		{path: "$[0][1]", want: []pathStep{{index: 0}, {index: 1}}},
+		{path: "$['content-type']", want: []pathStep{{name: "content-type", isKey: true}}},
+		{path: `$["a.b"].c`, want: []pathStep{{name: "a.b", isKey: true}, {name: "c", isKey: true}}},
+		{path: "$['a]b']", want: []pathStep{{name: "a]b", isKey: true}}},
+		{path: "$['']", want: []pathStep{{name: "", isKey: true}}},
+		{path: "$.ünïcode", want: []pathStep{{name: "ünïcode", isKey: true}}},
+
+		{path: "", wantErr: true},
+		{path: "status", wantErr: true},
+		{path: "$.", wantErr: true},
+		{path: "$..a", wantErr: true},
+		{path: "$[", wantErr: true},
+		{path: "$[]", wantErr: true},
+		{path: "$[abc]", wantErr: true},
+		{path: "$[1.5]", wantErr: true},
+		{path: "$['open", wantErr: true},
+		{path: "$['mixed\"]", wantErr: true},
+		{path: "$a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parsePath(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePath(%q) = %+v, want an error", tt.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePath(%q) failed: %v", tt.path, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePath(%q) = %+v, want %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	var doc interface{}
+	err := json.Unmarshal([]byte(`{
+		"status": "ok",
+		"count": 3,
+		"empty": null,
+		"a.b": {"c": true},
+		"items": [{"id": 1}, {"id": 2}, {"id": 3}],
+		"nested": [[10, 20], [30]]
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   string
+		want   interface{}
+		exists bool
+	}{
+		{"$", doc, true},
+		{"$.status", "ok", true},
+		{"$.count", 3.0, true},
+		{"$.empty", nil, true},
+		{`$["a.b"].c`, true, true},
+		{"$.items[0].id", 1.0, true},
+		{"$.items[-1].id", 3.0, true},
+		{"$.items[-3].id", 1.0, true},
+		{"$.nested[0][1]", 20.0, true},
+
+		{"$.missing", nil, false},
+		{"$.a.b", nil, false},
+		{"$.items[3]", nil, false},
+		{"$.items[-4]", nil, false},
+		{"$.status.length", nil, false},
+		{"$.status[0]", nil, false},
+		{"$.items.id", nil, false},
+		{"$.empty.x", nil, false},
+		{"$[0]", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			steps, err := parsePath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, exists := lookupPath(doc, steps)
+			if exists != tt.exists {
+				t.Fatalf("lookupPath(%q) exists = %v, want %v", tt.path, exists, tt.exists)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lookupPath(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckJSON(t *testing.T) {
+	no := false
+	a := &types.Assertions{JSON: []types.JSONAssertion{
+		{Path: "$.status", Equals: "ok"},
+		{Path: "$.count", Equals: 3},
+		{Path: "$.empty", Equals: nil},
+		{Path: "$.error", Exists: &no},
+		{Path: "$.items[-1].id", Equals: 2},
+		{Path: "$.missing"},
+	}}
+	resp := Response{StatusCode: 200, Body: []byte(`{"status": "ok", "count": 3, "empty": null, "items": [{"id": 1}, {"id": 2}]}`)}
+
+	failures := Check(a, resp)
+	want := []types.AssertionFailure{
+		{Check: "json $.missing", Expected: "a value", Actual: "missing"},
+	}
+	if !reflect.DeepEqual(failures, want) {
+		t.Errorf("Check() = %+v, want %+v", failures, want)
+	}
+}
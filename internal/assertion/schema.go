@@ -0,0 +1,50 @@
+package assertion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// inlineSchemaURL identifies schemas embedded in a job
+const inlineSchemaURL = "urn:tempo:inline-schema"
+
+// compileSchema compiles an inline schema or loads one from a file path.
+// Files are read again on every check, so edits apply without re-adding the job.
+func compileSchema(schema interface{}) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+
+	if path, ok := schema.(string); ok {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON schema path %q: %v", path, err)
+		}
+		compiled, err := compiler.Compile(abs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON schema %s: %v", path, err)
+		}
+		return compiled, nil
+	}
+
+	// Schemas decoded from YAML are not plain JSON values yet
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %v", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %v", err)
+	}
+
+	if err := compiler.AddResource(inlineSchemaURL, doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %v", err)
+	}
+	compiled, err := compiler.Compile(inlineSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %v", err)
+	}
+	return compiled, nil
+}
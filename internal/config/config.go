@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"tempo/internal/redact"
+	"tempo/internal/types"
+)
+
+// FileName is the name of the configuration file inside the data directory
+const FileName = "config.json"
+
+// Config holds settings shared by every tempo command
+type Config struct {
+	Storage string         `json:"storage,omitempty"` // storage backend: "json" or "sqlite"
+	Redact  *redact.Config `json:"redact,omitempty"`  // extra rules for masking sensitive data in output
+
+	HTTP      *types.HTTPConfig `json:"http,omitempty"`      // HTTP client settings of jobs that don't set them
+	Execution *Execution        `json:"execution,omitempty"` // resource limits of the scheduler
+	History   *History          `json:"history,omitempty"`   // retention of the execution history
+
+	Alerts *types.AlertPolicy `json:"alerts,omitempty"` // alert channels and rules of every job
+
+	Dashboard *Dashboard `json:"dashboard,omitempty"` // access to the web dashboard
+}
+
+// httpConfig is types.HTTPConfig with the snake_case keys of the configuration
+// file. Jobs store the same settings under their field names, like the rest of
+// a job; the same goes for the alert types below.
+type httpConfig struct {
+	Timeout            types.Duration `json:"timeout,omitempty"`
+	FollowRedirects    *bool          `json:"follow_redirects,omitempty"`
+	MaxRedirects       int            `json:"max_redirects,omitempty"`
+	CACert             string         `json:"ca_cert,omitempty"`
+	ClientCert         string         `json:"client_cert,omitempty"`
+	ClientKey          string         `json:"client_key,omitempty"`
+	InsecureSkipVerify *bool          `json:"insecure_skip_verify,omitempty"`
+	Proxy              string         `json:"proxy,omitempty"`
+	Version            string         `json:"version,omitempty"`
+}
+
+// alertChannel is types.AlertChannel with the keys of the configuration file
+type alertChannel struct {
+	Name       string            `json:"name,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	SMTPServer string            `json:"smtp_server,omitempty"`
+	Username   string            `json:"username,omitempty"`
+	Password   string            `json:"password,omitempty"`
+	From       string            `json:"from,omitempty"`
+	To         []string          `json:"to,omitempty"`
+}
+
+// alertPolicy is types.AlertPolicy with the keys of the configuration file
+type alertPolicy struct {
+	Channels []alertChannel `json:"channels,omitempty"`
+	After    int            `json:"after,omitempty"`
+	Recovery *bool          `json:"recovery,omitempty"`
+	Repeat   types.Duration `json:"repeat,omitempty"`
+	Disabled bool           `json:"disabled,omitempty"`
+}
+
+func newAlertPolicy(p *types.AlertPolicy) *alertPolicy {
+	if p == nil {
+		return nil
+	}
+	file := &alertPolicy{After: p.After, Recovery: p.Recovery, Repeat: p.Repeat, Disabled: p.Disabled}
+	for _, channel := range p.Channels {
+		file.Channels = append(file.Channels, alertChannel(channel))
+	}
+	return file
+}
+
+func (p *alertPolicy) policy() *types.AlertPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := &types.AlertPolicy{After: p.After, Recovery: p.Recovery, Repeat: p.Repeat, Disabled: p.Disabled}
+	for _, channel := range p.Channels {
+		policy.Channels = append(policy.Channels, types.AlertChannel(channel))
+	}
+	return policy
+}
+
+// plainConfig is Config without its JSON methods
+type plainConfig Config
+
+// configFile is the layout of the configuration file
+type configFile struct {
+	*plainConfig
+	HTTP   *httpConfig  `json:"http,omitempty"`
+	Alerts *alertPolicy `json:"alerts,omitempty"`
+}
+
+// MarshalJSON writes the configuration with snake_case keys throughout
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(configFile{
+		plainConfig: (*plainConfig)(&c),
+		HTTP:        (*httpConfig)(c.HTTP),
+		Alerts:      newAlertPolicy(c.Alerts),
+	})
+}
+
+// UnmarshalJSON reads a configuration written with snake_case keys
+func (c *Config) UnmarshalJSON(data []byte) error {
+	file := configFile{plainConfig: (*plainConfig)(c)}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	c.HTTP = (*types.HTTPConfig)(file.HTTP)
+	c.Alerts = file.Alerts.policy()
+	return nil
+}
+
+// Execution limits the resources the scheduler uses to run jobs
+type Execution struct {
+	Workers         int `json:"workers,omitempty"`            // executions running at the same time, zero means the default
+	QueueSize       int `json:"queue_size,omitempty"`         // executions waiting for a worker before new ones are skipped, zero means the default
+	MaxConnsPerHost int `json:"max_conns_per_host,omitempty"` // connections to a single host, zero means unlimited
+}
+
+// Validate checks that the limits are usable
+func (e *Execution) Validate() error {
+	if e == nil {
+		return nil
+	}
+	if e.Workers < 0 || e.QueueSize < 0 || e.MaxConnsPerHost < 0 {
+		return fmt.Errorf("execution limits cannot be negative")
+	}
+	return nil
+}
+
+// History limits how much execution history is kept. Old history is removed
+// in whole segments, so slightly more than the limits may be kept.
+type History struct {
+	MaxAge     types.Duration `json:"max_age,omitempty"`     // age of the oldest execution kept, zero means the default
+	MaxRecords int            `json:"max_records,omitempty"` // executions kept, zero means unlimited
+}
+
+// Validate checks that the limits are usable
+func (h *History) Validate() error {
+	if h == nil {
+		return nil
+	}
+	if h.MaxAge < 0 || h.MaxRecords < 0 {
+		return fmt.Errorf("history limits cannot be negative")
+	}
+	return nil
+}
+
+// MinDashboardTokenLength is the length of the shortest dashboard token accepted
+const MinDashboardTokenLength = 16
+
+// Dashboard controls access to the web dashboard
+type Dashboard struct {
+	Token string   `json:"token,omitempty"` // required by API requests; without one a generated token is used and only loopback addresses may be bound
+	Hosts []string `json:"hosts,omitempty"` // host names the dashboard is reached by besides localhost and IP addresses, e.g. through a reverse proxy
+}
+
+// Validate checks that the token is long enough
+func (d *Dashboard) Validate() error {
+	if d == nil || d.Token == "" {
+		return nil
+	}
+	if len(d.Token) < MinDashboardTokenLength {
+		return fmt.Errorf("dashboard token must be at least %d characters", MinDashboardTokenLength)
+	}
+	return nil
+}
+
+// Load reads the configuration from the data directory.
+// A missing file yields the default configuration.
+func Load(dataDir string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(filepath.Join(dataDir, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// Save writes the configuration to the data directory
+func (c *Config) Save(dataDir string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dataDir, FileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+
+	return nil
+}
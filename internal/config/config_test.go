@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"tempo/internal/types"
+	"testing"
+	"time"
+)
+
+func TestConfigFileKeys(t *testing.T) {
+	data := []byte(`{
+		"storage": "sqlite",
+		"http": {"timeout": "30s", "follow_redirects": false, "ca_cert": "/etc/ssl/corp-ca.pem", "insecure_skip_verify": true},
+		"history": {"max_records": 1000},
+		"alerts": {
+			"channels": [{"name": "mail", "type": "email", "smtp_server": "smtp.example.com:587", "to": ["ops@example.com"]}],
+			"after": 3,
+			"repeat": "6h"
+		}
+	}`)
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	follow, insecure := false, true
+	want := &types.HTTPConfig{
+		Timeout:            types.Duration(30 * time.Second),
+		FollowRedirects:    &follow,
+		CACert:             "/etc/ssl/corp-ca.pem",
+		InsecureSkipVerify: &insecure,
+	}
+	if !reflect.DeepEqual(cfg.HTTP, want) {
+		t.Errorf("HTTP = %+v, want %+v", cfg.HTTP, want)
+	}
+	wantAlerts := &types.AlertPolicy{
+		Channels: []types.AlertChannel{{Name: "mail", Type: types.ChannelEmail, SMTPServer: "smtp.example.com:587", To: []string{"ops@example.com"}}},
+		After:    3,
+		Repeat:   types.Duration(6 * time.Hour),
+	}
+	if !reflect.DeepEqual(cfg.Alerts, wantAlerts) {
+		t.Errorf("Alerts = %+v, want %+v", cfg.Alerts, wantAlerts)
+	}
+	if cfg.Storage != "sqlite" || cfg.History == nil || cfg.History.MaxRecords != 1000 {
+		t.Errorf("other settings = %q, %+v", cfg.Storage, cfg.History)
+	}
+
+	saved, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"storage"`, `"http"`, `"follow_redirects"`, `"ca_cert"`, `"insecure_skip_verify"`, `"max_records"`, `"smtp_server"`, `"after"`} {
+		if !strings.Contains(string(saved), key) {
+			t.Errorf("saved configuration lacks %s:\n%s", key, saved)
+		}
+	}
+
+	var again Config
+	if err := json.Unmarshal(saved, &again); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, cfg) {
+		t.Errorf("configuration changed when saved and loaded again: %+v, want %+v", again, cfg)
+	}
+}
@@ -0,0 +1,136 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"tempo/internal/types"
+	"time"
+)
+
+// ErrNotRunning is returned when no scheduler is listening on the control socket
+var ErrNotRunning = errors.New("no running scheduler found. Start one with 'tempo start'")
+
+// Client talks to a running scheduler through its control socket
+type Client struct {
+	path string
+}
+
+func NewClient(dataDir string) *Client {
+	return &Client{path: SocketPath(dataDir)}
+}
+
+// Follow streams live events for the job (or every job when jobID is empty)
+// to fn until the connection is closed or fn returns an error
+func (c *Client) Follow(jobID string, fn func(types.Event) error) error {
+	conn, reader, _, err := c.roundTrip(Request{Op: OpFollow, JobID: jobID})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return nil
+		}
+
+		var event types.Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("invalid event from scheduler: %v", err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
+// Status returns the state of the running scheduler
+func (c *Client) Status() (*Status, error) {
+	var status Status
+	if err := c.call(Request{Op: OpStatus}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Jobs returns the jobs loaded in the running scheduler
+func (c *Client) Jobs() ([]types.ScheduledJob, error) {
+	var jobs []types.ScheduledJob
+	err := c.call(Request{Op: OpJobs}, &jobs)
+	return jobs, err
+}
+
+// Trigger runs a job in the scheduler right now and returns the last attempt.
+// A failed webhook call is reported through the execution's Error field.
+func (c *Client) Trigger(jobID string) (types.Execution, error) {
+	var exec types.Execution
+	err := c.call(Request{Op: OpTrigger, JobID: jobID}, &exec)
+	return exec, err
+}
+
+// Reload makes the scheduler pick up changes made to the job storage
+func (c *Client) Reload() (types.ReloadSummary, error) {
+	var summary types.ReloadSummary
+	err := c.call(Request{Op: OpReload}, &summary)
+	return summary, err
+}
+
+// Executions returns recent executions recorded by the scheduler
+func (c *Client) Executions(jobID string, since time.Time, limit int) ([]types.Execution, error) {
+	var executions []types.Execution
+	err := c.call(Request{Op: OpExecutions, JobID: jobID, Since: since, Limit: limit}, &executions)
+	return executions, err
+}
+
+// call sends a single request and decodes the response payload into out
+func (c *Client) call(req Request, out interface{}) error {
+	conn, _, data, err := c.roundTrip(req)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid response from scheduler: %v", err)
+	}
+	return nil
+}
+
+// roundTrip sends the request and reads the first response line.
+// The connection is left open on success so streams can continue reading.
+func (c *Client) roundTrip(req Request) (net.Conn, *bufio.Reader, json.RawMessage, error) {
+	conn, err := net.Dial("unix", c.path)
+	if err != nil {
+		return nil, nil, nil, ErrNotRunning
+	}
+
+	if err := writeJSON(conn, req); err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("invalid response from scheduler: %v", err)
+	}
+	if !resp.OK {
+		conn.Close()
+		return nil, nil, nil, errors.New(resp.Error)
+	}
+
+	return conn, reader, resp.Data, nil
+}
@@ -0,0 +1,49 @@
+package control
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"tempo/internal/types"
+	"time"
+)
+
+// SocketName is the name of the control socket inside the data directory
+const SocketName = "tempo.sock"
+
+// Operations understood by the control server
+const (
+	OpFollow     = "follow"
+	OpStatus     = "status"
+	OpJobs       = "jobs"
+	OpTrigger    = "trigger"
+	OpReload     = "reload"
+	OpExecutions = "executions"
+)
+
+// Request is sent by a client as a single JSON line after connecting
+type Request struct {
+	Op    string    `json:"op"`
+	JobID string    `json:"job_id,omitempty"`
+	Since time.Time `json:"since,omitempty"`
+	Limit int       `json:"limit,omitempty"`
+}
+
+// Response is the JSON line a server answers every request with
+type Response struct {
+	OK    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Status describes a running scheduler
+type Status struct {
+	PID       int                  `json:"pid"`
+	StartedAt time.Time            `json:"started_at"`
+	Jobs      []types.ScheduledJob `json:"jobs"`
+	Pool      *types.PoolStats     `json:"pool,omitempty"` // nil when reported by an older scheduler
+}
+
+// SocketPath returns the control socket location for a data directory
+func SocketPath(dataDir string) string {
+	return filepath.Join(dataDir, SocketName)
+}
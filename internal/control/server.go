@@ -0,0 +1,230 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"tempo/internal/events"
+	"tempo/internal/logging"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"time"
+)
+
+// Backend is the running scheduler served by the control server
+type Backend interface {
+	Jobs() []types.ScheduledJob
+	Trigger(jobID string) (types.Execution, error)
+	Reload() (types.ReloadSummary, error)
+	PoolStats() types.PoolStats
+}
+
+// HistoryReader gives access to recorded executions
+type HistoryReader interface {
+	QueryExecutions(filter storage.HistoryFilter) ([]types.Execution, error)
+}
+
+// Server exposes a running scheduler over a Unix domain socket
+type Server struct {
+	path      string
+	bus       *events.Bus
+	backend   Backend
+	history   HistoryReader
+	listener  net.Listener
+	startedAt time.Time
+
+	wg    sync.WaitGroup
+	mutex sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// Listen binds the control socket in the data directory.
+// It fails if another scheduler is already serving on it and
+// removes the socket left behind by one that crashed.
+func Listen(dataDir string, bus *events.Bus, backend Backend, history HistoryReader) (*Server, error) {
+	path := SocketPath(dataDir)
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a scheduler is already running (socket %s)", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %v", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %v", err)
+	}
+
+	return &Server{
+		path:      path,
+		bus:       bus,
+		backend:   backend,
+		history:   history,
+		listener:  listener,
+		startedAt: time.Now(),
+		conns:     make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Serve accepts client connections until the server is closed
+func (s *Server) Serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mutex.Lock()
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.forget(conn)
+			s.handle(conn)
+		}()
+	}
+}
+
+// Close stops accepting clients, disconnects the current ones and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	s.mutex.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) forget(conn net.Conn) {
+	conn.Close()
+
+	s.mutex.Lock()
+	delete(s.conns, conn)
+	s.mutex.Unlock()
+}
+
+func (s *Server) handle(conn net.Conn) {
+	var req Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		writeJSON(conn, Response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	switch req.Op {
+	case OpFollow:
+		s.follow(conn, req)
+	case OpStatus:
+		pool := s.backend.PoolStats()
+		reply(conn, Status{
+			PID:       os.Getpid(),
+			StartedAt: s.startedAt,
+			Jobs:      s.backend.Jobs(),
+			Pool:      &pool,
+		})
+	case OpJobs:
+		reply(conn, s.backend.Jobs())
+	case OpTrigger:
+		exec, err := s.backend.Trigger(req.JobID)
+		if exec.ID == "" && err != nil {
+			fail(conn, err)
+			return
+		}
+		// A failed webhook is still a completed trigger, the execution carries the error
+		reply(conn, exec)
+	case OpReload:
+		summary, err := s.backend.Reload()
+		if err != nil {
+			fail(conn, err)
+			return
+		}
+		reply(conn, summary)
+	case OpExecutions:
+		executions, err := s.history.QueryExecutions(storage.HistoryFilter{
+			JobID: req.JobID,
+			Since: req.Since,
+			Limit: req.Limit,
+		})
+		if err != nil {
+			fail(conn, err)
+			return
+		}
+		reply(conn, executions)
+	default:
+		writeJSON(conn, Response{Error: fmt.Sprintf("unknown operation: %s", req.Op)})
+	}
+}
+
+// follow streams scheduler events to the client until it disconnects
+func (s *Server) follow(conn net.Conn, req Request) {
+	events, unsubscribe := s.bus.Subscribe()
+	defer unsubscribe()
+
+	// Detect the client hanging up even when no events are flowing
+	closed := make(chan struct{})
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				close(closed)
+				return
+			}
+		}
+	}()
+
+	if err := writeJSON(conn, Response{OK: true}); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case event := <-events:
+			if req.JobID != "" && event.JobID != req.JobID {
+				continue
+			}
+			if err := writeJSON(conn, event); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// reply answers a request successfully with the given payload
+func reply(conn net.Conn, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		writeJSON(conn, Response{Error: fmt.Sprintf("failed to encode response: %v", err)})
+		return
+	}
+	writeJSON(conn, Response{OK: true, Data: payload})
+}
+
+// fail answers a request with an error
+func fail(conn net.Conn, err error) {
+	writeJSON(conn, Response{Error: err.Error()})
+}
+
+func writeJSON(conn net.Conn, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("Failed to encode control response", logging.Err(err))
+		return err
+	}
+	_, err = conn.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+const (
+	// PIDFileName holds the PID of the running scheduler and is locked while it runs
+	PIDFileName = "tempo.pid"
+	// LogFileName receives the logs of a background scheduler
+	LogFileName = "tempo.log"
+	// OutFileName receives what a background scheduler writes to stdout and
+	// stderr outside of its logger, such as panics. It is never rotated.
+	OutFileName = "tempo.out"
+	// EnvChild is set in the environment of a scheduler spawned by Spawn
+	EnvChild = "TEMPO_DAEMON"
+	// EnvSecretFD names the inherited file descriptor a spawned scheduler
+	// reads its secret from
+	EnvSecretFD = "TEMPO_DAEMON_SECRET_FD"
+)
+
+// ErrNotSupported is returned on platforms without daemon support
+var ErrNotSupported = errors.New("background mode is not supported on this platform, use --foreground")
+
+// PIDFilePath returns the PID file location for a data directory
+func PIDFilePath(dataDir string) string {
+	return filepath.Join(dataDir, PIDFileName)
+}
+
+// LogFilePath returns the daemon log file location for a data directory
+func LogFilePath(dataDir string) string {
+	return filepath.Join(dataDir, LogFileName)
+}
+
+// OutFilePath returns the location of a background scheduler's stdout and
+// stderr for a data directory
+func OutFilePath(dataDir string) string {
+	return filepath.Join(dataDir, OutFileName)
+}
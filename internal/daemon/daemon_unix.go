@@ -0,0 +1,177 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// PIDFile is the locked PID file held by a running scheduler
+type PIDFile struct {
+	file *os.File
+}
+
+// AcquirePIDFile locks the PID file in the data directory and writes the
+// current PID to it. It fails if another scheduler holds the lock.
+func AcquirePIDFile(dataDir string) (*PIDFile, error) {
+	path := PIDFilePath(dataDir)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PID file: %v", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if pid, running, _ := ReadPID(dataDir); running {
+			return nil, fmt.Errorf("scheduler is already running (pid %d)", pid)
+		}
+		return nil, fmt.Errorf("failed to lock PID file: %v", err)
+	}
+
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write PID file: %v", err)
+	}
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write PID file: %v", err)
+	}
+
+	return &PIDFile{file: file}, nil
+}
+
+// Release clears the PID file and drops the lock. The file itself stays:
+// removing it while locked would let a new scheduler lock a fresh file at
+// the same path while another one still waits on the old one.
+func (p *PIDFile) Release() error {
+	p.file.Truncate(0)
+	return p.file.Close()
+}
+
+// ReadPID returns the PID recorded in the data directory and whether
+// that scheduler is still running, i.e. still holds the lock
+func ReadPID(dataDir string) (int, bool, error) {
+	file, err := os.Open(PIDFilePath(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to open PID file: %v", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read PID file: %v", err)
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return pid, true, nil
+	}
+	if err != nil {
+		return pid, false, fmt.Errorf("failed to check PID file lock: %v", err)
+	}
+	syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	return pid, false, nil
+}
+
+// Spawn starts the current executable detached from the terminal in its
+// own session, with stdout and stderr appended to the data directory's out
+// file. They don't share the log file: the child rotates that one, and the
+// inherited descriptor would keep writing to the rotated file.
+// A non-empty secret is handed over through a pipe, never the environment
+// or arguments, and read by the child with ReadSecret.
+func Spawn(dataDir string, args []string, secret []byte) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate executable: %v", err)
+	}
+
+	outFile, err := os.OpenFile(OutFilePath(dataDir), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open output file: %v", err)
+	}
+	defer outFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Env = append(os.Environ(), EnvChild+"=1")
+	cmd.Stdout = outFile
+	cmd.Stderr = outFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if len(secret) > 0 {
+		reader, err := secretPipe(secret)
+		if err != nil {
+			return 0, err
+		}
+		defer reader.Close()
+
+		// ExtraFiles start at descriptor 3 in the child
+		cmd.ExtraFiles = []*os.File{reader}
+		cmd.Env = append(cmd.Env, EnvSecretFD+"=3")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start scheduler process: %v", err)
+	}
+
+	pid := cmd.Process.Pid
+	// The child outlives us, don't leave it waiting to be reaped
+	cmd.Process.Release()
+
+	return pid, nil
+}
+
+// secretPipe returns the read end of a pipe holding the secret. The secret
+// fits in the pipe buffer, so it is written before the reader starts.
+func secretPipe(secret []byte) (*os.File, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret pipe: %v", err)
+	}
+	_, err = writer.Write(secret)
+	writer.Close()
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("failed to write secret pipe: %v", err)
+	}
+	return reader, nil
+}
+
+// ReadSecret returns the secret handed over by Spawn, or nil if there is none
+func ReadSecret() ([]byte, error) {
+	value := os.Getenv(EnvSecretFD)
+	if value == "" {
+		return nil, nil
+	}
+	os.Unsetenv(EnvSecretFD)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", EnvSecretFD, value)
+	}
+	file := os.NewFile(uintptr(fd), "secret")
+	defer file.Close()
+
+	secret, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret from parent: %v", err)
+	}
+	return secret, nil
+}
+
+// Terminate asks the scheduler process to shut down gracefully
+func Terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package daemon
+
+// PIDFile is not supported on Windows, a scheduler is never locked
+type PIDFile struct{}
+
+func AcquirePIDFile(dataDir string) (*PIDFile, error) {
+	return &PIDFile{}, nil
+}
+
+func (p *PIDFile) Release() error {
+	return nil
+}
+
+func ReadPID(dataDir string) (int, bool, error) {
+	return 0, false, nil
+}
+
+func Spawn(dataDir string, args []string, secret []byte) (int, error) {
+	return 0, ErrNotSupported
+}
+
+func ReadSecret() ([]byte, error) {
+	return nil, nil
+}
+
+func Terminate(pid int) error {
+	return ErrNotSupported
+}
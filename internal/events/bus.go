@@ -0,0 +1,57 @@
+package events
+
+import (
+	"sync"
+	"tempo/internal/types"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind before events are dropped
+const subscriberBuffer = 64
+
+// Bus fans out scheduler events to any number of subscribers
+type Bus struct {
+	mutex       sync.RWMutex
+	subscribers map[chan types.Event]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan types.Event]struct{}),
+	}
+}
+
+// Publish delivers the event to every subscriber without blocking.
+// Subscribers that are not keeping up miss the event.
+func (b *Bus) Publish(event types.Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving published events and a function
+// that unsubscribes and closes the channel
+func (b *Bus) Subscribe() (<-chan types.Event, func()) {
+	ch := make(chan types.Event, subscriberBuffer)
+
+	b.mutex.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mutex.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subscribers, ch)
+			b.mutex.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
@@ -0,0 +1,130 @@
+package jobfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tempo/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported job file formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// DetectFormat picks the format for a file from its extension,
+// defaulting to JSON for unknown extensions and stdin/stdout ("-")
+func DetectFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatJSON
+	}
+}
+
+// Encode serializes jobs sorted by ID. JSON output is a single array,
+// YAML output is a stream with one document per job.
+func Encode(jobs []types.Job, format string) ([]byte, error) {
+	sorted := make([]types.Job, len(jobs))
+	copy(sorted, jobs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(sorted, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON: %v", err)
+		}
+		return append(data, '\n'), nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		for _, job := range sorted {
+			if err := encoder.Encode(job); err != nil {
+				return nil, fmt.Errorf("failed to marshal YAML: %v", err)
+			}
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to marshal YAML: %v", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// Decode parses jobs. JSON input may be an array of jobs or a single job.
+// YAML input may hold several documents, each a list of jobs or a single job.
+func Decode(data []byte, format string) ([]types.Job, error) {
+	switch format {
+	case FormatJSON:
+		trimmed := bytes.TrimSpace(data)
+		if len(trimmed) > 0 && trimmed[0] == '{' {
+			var job types.Job
+			if err := json.Unmarshal(trimmed, &job); err != nil {
+				return nil, fmt.Errorf("failed to parse JSON: %v", err)
+			}
+			return []types.Job{job}, nil
+		}
+
+		var jobs []types.Job
+		if err := json.Unmarshal(data, &jobs); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %v", err)
+		}
+		return jobs, nil
+	case FormatYAML:
+		return decodeYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func decodeYAML(data []byte) ([]types.Job, error) {
+	var jobs []types.Job
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for doc := 1; ; doc++ {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse YAML document %d: %v", doc, err)
+		}
+
+		// Skip empty documents such as a trailing "---"
+		if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		content := node.Content[0]
+		switch content.Kind {
+		case yaml.SequenceNode:
+			var docJobs []types.Job
+			if err := content.Decode(&docJobs); err != nil {
+				return nil, fmt.Errorf("failed to parse YAML document %d: %v", doc, err)
+			}
+			jobs = append(jobs, docJobs...)
+		case yaml.MappingNode:
+			var job types.Job
+			if err := content.Decode(&job); err != nil {
+				return nil, fmt.Errorf("failed to parse YAML document %d: %v", doc, err)
+			}
+			jobs = append(jobs, job)
+		default:
+			return nil, fmt.Errorf("YAML document %d must be a job or a list of jobs", doc)
+		}
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Defaults of the log file rotation
+const (
+	DefaultMaxSize  = 10 << 20 // bytes
+	DefaultMaxFiles = 5
+)
+
+// File is a log file that is rotated when it grows beyond a size. The full
+// file is renamed to <path>.1, older ones move up to <path>.<maxFiles> and
+// the oldest is removed.
+type File struct {
+	path     string
+	maxSize  int64 // zero never rotates
+	maxFiles int
+
+	mutex  sync.Mutex
+	file   *os.File // nil after a failed rotation, reopened by the next write
+	size   int64
+	closed bool
+}
+
+// OpenFile opens the log file for appending, creating it if needed
+func OpenFile(path string, maxSize int64, maxFiles int) (*File, error) {
+	if maxSize < 0 {
+		return nil, fmt.Errorf("invalid log file size %d", maxSize)
+	}
+	if maxFiles < 1 {
+		return nil, fmt.Errorf("at least one rotated log file must be kept")
+	}
+
+	f := &File{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Path returns the location of the current log file
+func (f *File) Path() string {
+	return f.path
+}
+
+// Write appends to the log file, rotating it first if the write would take
+// it beyond the size limit. A single record is never split across files.
+func (f *File) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file != nil && f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			// Keep logging to the full file rather than losing records
+			fmt.Fprintf(os.Stderr, "Error rotating log file: %v\n", err)
+		}
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (f *File) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file aside and starts a new one. If no file could
+// be opened afterwards f.file is nil. The caller must hold f.mutex.
+func (f *File) rotate() error {
+	os.Remove(f.rotated(f.maxFiles))
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(f.rotated(i), f.rotated(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(f.path, f.rotated(1)); err != nil {
+		// Without the rename, carry on appending to the same file
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return f.open()
+}
+
+func (f *File) rotated(n int) string {
+	return fmt.Sprintf("%s.%d", f.path, n)
+}
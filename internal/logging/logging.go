@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats of log records
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Keys of the fields shared by records about jobs and executions
+const (
+	KeyJobID       = "job_id"
+	KeyExecutionID = "execution_id"
+	KeyAttempt     = "attempt"
+	KeyStatus      = "status"
+	KeyDurationMs  = "duration_ms"
+	KeyError       = "error"
+)
+
+// Options select the format and verbosity of the scheduler's logs
+type Options struct {
+	Format string // text or json, text if empty
+	Level  string // debug, info, warn or error, info if empty
+}
+
+// Validate checks the format and level
+func (o Options) Validate() error {
+	if _, err := parseFormat(o.Format); err != nil {
+		return err
+	}
+	_, err := ParseLevel(o.Level)
+	return err
+}
+
+// New creates a logger writing records in the selected format to w
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	format, err := parseFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	if format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	}
+	return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+}
+
+// ParseLevel reads a level name, case-insensitively. Empty means info.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q. Use debug, info, warn or error", name)
+	}
+}
+
+func parseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("invalid log format %q. Use text or json", format)
+	}
+}
+
+// Err is the attribute of an error, omitted if the error is nil
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.String(KeyError, err.Error())
+}
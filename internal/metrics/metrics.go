@@ -0,0 +1,247 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"tempo/internal/logging"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is where the metrics are served
+const Path = "/metrics"
+
+// durationBuckets extend the Prometheus defaults to the longest request timeouts jobs commonly use
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// Source is the running scheduler whose state is exported
+type Source interface {
+	Jobs() []types.ScheduledJob
+	PoolStats() types.PoolStats
+}
+
+// History is the execution history the last successes are read from, and
+// the jobs still stored beside it
+type History interface {
+	SummarizeExecutions(jobID string) (map[string]storage.JobHistory, error)
+	GetJob(id string) (types.Job, bool)
+}
+
+// Metrics exports execution counters and the scheduler's state in the
+// Prometheus format
+type Metrics struct {
+	registry *prometheus.Registry
+
+	executions  *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	retries     *prometheus.CounterVec
+	lastSuccess *prometheus.GaugeVec
+	queueWait   prometheus.Histogram
+
+	mutex     sync.Mutex
+	successes map[string]time.Time // last success of every job, behind lastSuccess
+}
+
+// New creates the execution metrics. Scheduler state is added with
+// RegisterScheduler once the scheduler exists.
+func New() *Metrics {
+	m := &Metrics{
+		registry:  prometheus.NewRegistry(),
+		successes: make(map[string]time.Time),
+
+		executions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tempo_executions_total",
+			Help: "Executions by job and outcome. Every attempt of a run counts.",
+		}, []string{"job_id", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tempo_execution_duration_seconds",
+			Help:    "Latency of executions that sent a request, by job.",
+			Buckets: durationBuckets,
+		}, []string{"job_id"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tempo_retries_total",
+			Help: "Attempts after the first one of a run, by job.",
+		}, []string{"job_id"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tempo_job_last_success_timestamp_seconds",
+			Help: "Unix time the job last succeeded.",
+		}, []string{"job_id"}),
+		queueWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tempo_execution_queue_wait_seconds",
+			Help:    "Time executions that got a worker waited for it.",
+			Buckets: durationBuckets,
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.executions,
+		m.duration,
+		m.retries,
+		m.lastSuccess,
+		m.queueWait,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// RegisterScheduler exports the jobs and workers of the scheduler, read on every scrape
+func (m *Metrics) RegisterScheduler(source Source) {
+	m.registry.MustRegister(&schedulerCollector{source: source})
+}
+
+// ObserveExecution counts an execution attempt. Its latency and queue wait
+// are only observed if it sent a request; attempts that failed to render or
+// never got a worker would skew them towards zero.
+func (m *Metrics) ObserveExecution(exec types.Execution, sent bool) {
+	m.executions.WithLabelValues(exec.JobID, string(exec.Status)).Inc()
+	if exec.Attempt > 1 {
+		m.retries.WithLabelValues(exec.JobID).Inc()
+	}
+	if exec.Status == types.StatusSuccess {
+		m.setLastSuccess(exec.JobID, exec.FinishedAt)
+	}
+
+	if !sent {
+		return
+	}
+	m.duration.WithLabelValues(exec.JobID).Observe(float64(exec.LatencyMs) / 1000)
+	m.queueWait.Observe(float64(exec.QueueMs) / 1000)
+}
+
+// ForgetJob drops the series of a job removed from the scheduler
+func (m *Metrics) ForgetJob(jobID string) {
+	m.executions.DeletePartialMatch(prometheus.Labels{"job_id": jobID})
+	m.duration.DeleteLabelValues(jobID)
+	m.retries.DeleteLabelValues(jobID)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.successes, jobID)
+	m.lastSuccess.DeleteLabelValues(jobID)
+}
+
+// LoadHistory sets the last success of every job from the execution history,
+// so it survives scheduler restarts. Successes observed since are kept, and
+// jobs deleted since their last run are left out.
+func (m *Metrics) LoadHistory(history History) error {
+	jobs, err := history.SummarizeExecutions("")
+	if err != nil {
+		return err
+	}
+
+	for jobID, job := range jobs {
+		if _, ok := history.GetJob(jobID); !ok {
+			continue
+		}
+		if !job.LastSuccess.IsZero() {
+			m.setLastSuccess(jobID, job.LastSuccess)
+		}
+	}
+	return nil
+}
+
+// setLastSuccess moves the job's last success forward to the given time
+func (m *Metrics) setLastSuccess(jobID string, finished time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !finished.After(m.successes[jobID]) {
+		return
+	}
+	m.successes[jobID] = finished
+	m.lastSuccess.WithLabelValues(jobID).Set(float64(finished.UnixNano()) / 1e9)
+}
+
+// Handler serves the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve exposes the metrics on the address in the background until the
+// returned server is closed
+func (m *Metrics) Serve(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %v", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, m.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Failed to serve metrics", logging.Err(err))
+		}
+	}()
+	return server, nil
+}
+
+// schedulerCollector reads the scheduler's jobs and workers on every scrape
+type schedulerCollector struct {
+	source Source
+}
+
+var (
+	jobsDesc = prometheus.NewDesc("tempo_jobs",
+		"Jobs loaded in the scheduler, by state: active, paused or completed (one-shot jobs that fired).", []string{"state"}, nil)
+	nextRunDesc = prometheus.NewDesc("tempo_job_next_run_timestamp_seconds",
+		"Unix time of the job's next scheduled run. Absent for paused jobs without a resume time and completed one-shot jobs.", []string{"job_id"}, nil)
+	inFlightDesc = prometheus.NewDesc("tempo_executions_in_flight",
+		"Executions currently running.", nil, nil)
+	queuedDesc = prometheus.NewDesc("tempo_execution_queue_length",
+		"Executions waiting for a free worker.", nil, nil)
+	queueSizeDesc = prometheus.NewDesc("tempo_execution_queue_capacity",
+		"Executions that may wait for a free worker.", nil, nil)
+	workersDesc = prometheus.NewDesc("tempo_execution_workers",
+		"Executions that may run at the same time.", nil, nil)
+	rejectedDesc = prometheus.NewDesc("tempo_execution_queue_rejected_total",
+		"Executions skipped because the queue was full.", nil, nil)
+)
+
+func (c *schedulerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- jobsDesc
+	ch <- nextRunDesc
+	ch <- inFlightDesc
+	ch <- queuedDesc
+	ch <- queueSizeDesc
+	ch <- workersDesc
+	ch <- rejectedDesc
+}
+
+func (c *schedulerCollector) Collect(ch chan<- prometheus.Metric) {
+	active, paused, completed := 0, 0, 0
+	for _, job := range c.source.Jobs() {
+		switch {
+		case job.Job.CompletedAt != nil:
+			completed++
+		case job.Paused:
+			paused++
+		default:
+			active++
+		}
+		if !job.NextRun.IsZero() {
+			ch <- prometheus.MustNewConstMetric(nextRunDesc, prometheus.GaugeValue, float64(job.NextRun.Unix()), job.Job.ID)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(jobsDesc, prometheus.GaugeValue, float64(active), "active")
+	ch <- prometheus.MustNewConstMetric(jobsDesc, prometheus.GaugeValue, float64(paused), "paused")
+	ch <- prometheus.MustNewConstMetric(jobsDesc, prometheus.GaugeValue, float64(completed), "completed")
+
+	stats := c.source.PoolStats()
+	ch <- prometheus.MustNewConstMetric(inFlightDesc, prometheus.GaugeValue, float64(stats.Busy))
+	ch <- prometheus.MustNewConstMetric(queuedDesc, prometheus.GaugeValue, float64(stats.Queued))
+	ch <- prometheus.MustNewConstMetric(queueSizeDesc, prometheus.GaugeValue, float64(stats.QueueSize))
+	ch <- prometheus.MustNewConstMetric(workersDesc, prometheus.GaugeValue, float64(stats.Workers))
+	ch <- prometheus.MustNewConstMetric(rejectedDesc, prometheus.CounterValue, float64(stats.Rejected))
+}
@@ -0,0 +1,181 @@
+package metrics
+
+import (
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gather returns the value of every series in the registry, keyed by metric
+// name and label values. Histograms are reported by their sample count.
+func gather(t *testing.T, m *Metrics) map[string]float64 {
+	t.Helper()
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range metric.GetLabel() {
+				key += " " + label.GetValue()
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				series[key] = metric.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				series[key] = metric.GetGauge().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				series[key] = float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return series
+}
+
+func TestObserveExecution(t *testing.T) {
+	m := New()
+	finished := time.Unix(1700000000, 0)
+
+	m.ObserveExecution(types.Execution{JobID: "a", Attempt: 1, Status: types.StatusFailure, LatencyMs: 1500, QueueMs: 200}, true)
+	m.ObserveExecution(types.Execution{JobID: "a", Attempt: 2, Status: types.StatusSuccess, LatencyMs: 250, FinishedAt: finished}, true)
+	// Attempts that never sent a request are counted without a latency
+	m.ObserveExecution(types.Execution{JobID: "a", Attempt: 1, Status: types.StatusFailure}, false)
+	m.ObserveExecution(types.Execution{JobID: "b", Attempt: 1, Status: types.StatusSkipped}, false)
+
+	series := gather(t, m)
+	want := map[string]float64{
+		"tempo_executions_total a failure":           2,
+		"tempo_executions_total a success":           1,
+		"tempo_executions_total b skipped":           1,
+		"tempo_retries_total a":                      1,
+		"tempo_execution_duration_seconds a":         2,
+		"tempo_execution_queue_wait_seconds":         2,
+		"tempo_job_last_success_timestamp_seconds a": float64(finished.Unix()),
+	}
+	for key, value := range want {
+		if series[key] != value {
+			t.Errorf("%s = %v, want %v", key, series[key], value)
+		}
+	}
+	for _, key := range []string{"tempo_execution_duration_seconds b", "tempo_retries_total b", "tempo_job_last_success_timestamp_seconds b"} {
+		if _, ok := series[key]; ok {
+			t.Errorf("unexpected series %s", key)
+		}
+	}
+
+	m.ForgetJob("a")
+	series = gather(t, m)
+	for _, key := range []string{"tempo_executions_total a failure", "tempo_executions_total a success", "tempo_retries_total a", "tempo_execution_duration_seconds a", "tempo_job_last_success_timestamp_seconds a"} {
+		if _, ok := series[key]; ok {
+			t.Errorf("%s is still exported after the job was removed", key)
+		}
+	}
+	if series["tempo_executions_total b skipped"] != 1 {
+		t.Error("forgetting a job removed the series of another job")
+	}
+
+	// A job added again starts from scratch
+	m.ObserveExecution(types.Execution{JobID: "a", Attempt: 1, Status: types.StatusSuccess, FinishedAt: finished.Add(-time.Hour)}, true)
+	if got := gather(t, m)["tempo_job_last_success_timestamp_seconds a"]; got != float64(finished.Add(-time.Hour).Unix()) {
+		t.Errorf("last success of a re-added job = %v, want %v", got, finished.Add(-time.Hour).Unix())
+	}
+}
+
+type fakeHistory struct {
+	summary map[string]storage.JobHistory
+	jobs    map[string]bool
+}
+
+func (h fakeHistory) SummarizeExecutions(string) (map[string]storage.JobHistory, error) {
+	return h.summary, nil
+}
+
+func (h fakeHistory) GetJob(id string) (types.Job, bool) {
+	return types.Job{ID: id}, h.jobs[id]
+}
+
+func TestLoadHistory(t *testing.T) {
+	m := New()
+	older, newer := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+
+	m.ObserveExecution(types.Execution{JobID: "a", Attempt: 1, Status: types.StatusSuccess, FinishedAt: newer}, true)
+	err := m.LoadHistory(fakeHistory{
+		summary: map[string]storage.JobHistory{
+			"a":       {Runs: 3, LastSuccess: older},
+			"b":       {Runs: 2, LastSuccess: older},
+			"never":   {Runs: 1},
+			"deleted": {Runs: 5, LastSuccess: older},
+		},
+		jobs: map[string]bool{"a": true, "b": true, "never": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series := gather(t, m)
+	if got := series["tempo_job_last_success_timestamp_seconds a"]; got != float64(newer.Unix()) {
+		t.Errorf("observed success was replaced by an older one from history: %v", got)
+	}
+	if got := series["tempo_job_last_success_timestamp_seconds b"]; got != float64(older.Unix()) {
+		t.Errorf("last success from history = %v, want %v", got, older.Unix())
+	}
+	for _, jobID := range []string{"never", "deleted"} {
+		if _, ok := series["tempo_job_last_success_timestamp_seconds "+jobID]; ok {
+			t.Errorf("unexpected last success for %s", jobID)
+		}
+	}
+}
+
+type fakeSource struct {
+	jobs  []types.ScheduledJob
+	stats types.PoolStats
+}
+
+func (s fakeSource) Jobs() []types.ScheduledJob { return s.jobs }
+func (s fakeSource) PoolStats() types.PoolStats { return s.stats }
+
+func TestSchedulerCollector(t *testing.T) {
+	next := time.Unix(1700000000, 0)
+	fired := next.Add(-time.Hour)
+
+	m := New()
+	m.RegisterScheduler(fakeSource{
+		jobs: []types.ScheduledJob{
+			{Job: types.Job{ID: "active"}, NextRun: next},
+			{Job: types.Job{ID: "paused-until"}, NextRun: next.Add(time.Hour), Paused: true},
+			{Job: types.Job{ID: "paused"}, Paused: true},
+			{Job: types.Job{ID: "completed", CompletedAt: &fired}},
+		},
+		stats: types.PoolStats{Workers: 4, Busy: 2, Queued: 1, QueueSize: 10, Rejected: 3},
+	})
+
+	series := gather(t, m)
+	want := map[string]float64{
+		"tempo_jobs active":                                 1,
+		"tempo_jobs paused":                                 2,
+		"tempo_jobs completed":                              1,
+		"tempo_job_next_run_timestamp_seconds active":       float64(next.Unix()),
+		"tempo_job_next_run_timestamp_seconds paused-until": float64(next.Add(time.Hour).Unix()),
+		"tempo_executions_in_flight":                        2,
+		"tempo_execution_queue_length":                      1,
+		"tempo_execution_queue_capacity":                    10,
+		"tempo_execution_workers":                           4,
+		"tempo_execution_queue_rejected_total":              3,
+	}
+	for key, value := range want {
+		if got, ok := series[key]; !ok || got != value {
+			t.Errorf("%s = %v, want %v", key, got, value)
+		}
+	}
+	for _, jobID := range []string{"paused", "completed"} {
+		if _, ok := series["tempo_job_next_run_timestamp_seconds "+jobID]; ok {
+			t.Errorf("unexpected next run for %s", jobID)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package redact
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"tempo/internal/types"
+)
+
+// Mask replaces redacted values
+const Mask = "[REDACTED]"
+
+// DefaultHeaders are header names whose values are always redacted
+var DefaultHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"Api-Key",
+	"X-Auth-Token",
+	"X-Access-Token",
+	"X-Csrf-Token",
+	"X-Amz-Security-Token",
+}
+
+// DefaultQueryParams are query string parameters whose values are always redacted
+var DefaultQueryParams = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"key",
+	"password",
+	"secret",
+	"sig",
+	"signature",
+	"token",
+}
+
+// DefaultPatterns match credentials in bodies, URLs and error messages.
+// Only the first capture group is redacted when a pattern has one.
+var DefaultPatterns = []string{
+	`(?i)"(?:password|passwd|secret|token|access_token|refresh_token|api_?key|client_secret)"\s*:\s*"([^"]*)"`,
+	`(?i)\b(?:password|passwd|secret|token|access_token|api_?key|client_secret)=([^&\s"]+)`,
+	`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`,
+}
+
+// Config extends the default rules. It is read from the "redact" key of
+// ~/.tempo/config.json.
+type Config struct {
+	Headers     []string `json:"headers,omitempty"`      // extra header names
+	QueryParams []string `json:"query_params,omitempty"` // extra query string parameters
+	Patterns    []string `json:"patterns,omitempty"`     // extra regular expressions for bodies and query strings
+}
+
+// Redactor masks sensitive data before it is logged, printed or recorded.
+// A nil Redactor applies the default rules.
+type Redactor struct {
+	headers  map[string]bool
+	params   map[string]bool
+	patterns []*regexp.Regexp
+	values   []string
+	disabled bool
+}
+
+// New returns a redactor applying the default rules plus the configured ones
+func New(cfg *Config) (*Redactor, error) {
+	r := &Redactor{
+		headers: make(map[string]bool),
+		params:  make(map[string]bool),
+	}
+
+	headers, params, patterns := DefaultHeaders, DefaultQueryParams, DefaultPatterns
+	if cfg != nil {
+		headers = append(append([]string{}, headers...), cfg.Headers...)
+		params = append(append([]string{}, params...), cfg.QueryParams...)
+		patterns = append(append([]string{}, patterns...), cfg.Patterns...)
+	}
+
+	for _, name := range headers {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range params {
+		r.params[strings.ToLower(name)] = true
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// Disabled returns a redactor that leaves everything as is, for --show-secrets
+func Disabled() *Redactor {
+	return &Redactor{disabled: true}
+}
+
+var defaultRedactor, _ = New(nil)
+
+func (r *Redactor) rules() *Redactor {
+	if r == nil {
+		return defaultRedactor
+	}
+	return r
+}
+
+// WithValues returns a copy of the redactor that also masks the given literal
+// values, such as secrets resolved for a request, including their URL-encoded
+// forms
+func (r *Redactor) WithValues(values ...string) *Redactor {
+	r = r.rules()
+	if r.disabled {
+		return r
+	}
+
+	copied := *r
+	copied.values = append([]string{}, r.values...)
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		query := url.QueryEscape(value)
+		encoded := []string{value, query, strings.ReplaceAll(query, "+", "%20"), url.PathEscape(value), (&url.URL{Path: value}).EscapedPath()}
+		for _, form := range encoded {
+			if !slices.Contains(copied.values, form) {
+				copied.values = append(copied.values, form)
+			}
+		}
+	}
+
+	// Longest first, so a value containing another one is masked whole
+	sort.SliceStable(copied.values, func(i, j int) bool {
+		return len(copied.values[i]) > len(copied.values[j])
+	})
+	return &copied
+}
+
+// urlPattern finds URLs embedded in free text, such as transport errors
+var urlPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+// String masks known values, URLs and pattern matches in free text such as
+// bodies and error messages
+func (r *Redactor) String(s string) string {
+	r = r.rules()
+	if r.disabled || s == "" {
+		return s
+	}
+	// Mask known values before URLs are re-encoded, which could hide them
+	s = r.maskValues(s)
+	return r.scrub(urlPattern.ReplaceAllStringFunc(s, r.redactURL))
+}
+
+// scrub masks known values and pattern matches
+func (r *Redactor) scrub(s string) string {
+	s = r.maskValues(s)
+	for _, re := range r.patterns {
+		s = replacePattern(re, s)
+	}
+	return s
+}
+
+// maskValues masks known values
+func (r *Redactor) maskValues(s string) string {
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, Mask)
+	}
+	return s
+}
+
+// Header masks the value of a sensitive header
+func (r *Redactor) Header(name, value string) string {
+	r = r.rules()
+	if r.disabled {
+		return value
+	}
+	if r.headers[http.CanonicalHeaderKey(name)] {
+		return Mask
+	}
+	return r.String(value)
+}
+
+// Headers returns a copy of the headers with sensitive values masked
+func (r *Redactor) Headers(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(headers))
+	for name, value := range headers {
+		redacted[name] = r.Header(name, value)
+	}
+	return redacted
+}
+
+// URL masks the password, sensitive query parameters and pattern matches in a URL
+func (r *Redactor) URL(raw string) string {
+	r = r.rules()
+	if r.disabled {
+		return raw
+	}
+	return r.scrub(r.redactURL(r.maskValues(raw)))
+}
+
+// redactURL masks the password and sensitive query parameters of a URL
+func (r *Redactor) redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), Mask)
+	}
+
+	if u.RawQuery != "" {
+		parts := strings.Split(u.RawQuery, "&")
+		for i, part := range parts {
+			name, _, found := strings.Cut(part, "=")
+			if !found {
+				continue
+			}
+			if key, err := url.QueryUnescape(name); err == nil && r.params[strings.ToLower(key)] {
+				parts[i] = name + "=" + Mask
+			}
+		}
+		u.RawQuery = strings.Join(parts, "&")
+	}
+
+	// Keep the mask readable instead of percent-encoding it
+	redacted := strings.ReplaceAll(u.String(), url.PathEscape(Mask), Mask)
+	return strings.ReplaceAll(redacted, url.QueryEscape(Mask), Mask)
+}
+
+// Job returns a copy of the job with its URL, headers, body, proxy and
+// alert channels redacted
+func (r *Redactor) Job(job types.Job) types.Job {
+	job.URL = r.URL(job.URL)
+	job.Headers = r.Headers(job.Headers)
+	job.Body = r.String(job.Body)
+	if job.HTTP != nil && job.HTTP.Proxy != "" {
+		settings := *job.HTTP
+		settings.Proxy = r.URL(settings.Proxy)
+		job.HTTP = &settings
+	}
+	if job.Alert != nil {
+		policy := *job.Alert
+		policy.Channels = r.AlertChannels(policy.Channels)
+		job.Alert = &policy
+	}
+	return job
+}
+
+// Restore undoes the redaction of a job that was edited in its redacted
+// form: every field still showing what Job made of the original gets the
+// original value back. Fields that were changed are kept as edited.
+func (r *Redactor) Restore(original, edited types.Job) types.Job {
+	shown := r.Job(original)
+
+	if edited.URL == shown.URL {
+		edited.URL = original.URL
+	}
+	if edited.Body == shown.Body {
+		edited.Body = original.Body
+	}
+	edited.Headers = restoreHeaders(original.Headers, shown.Headers, edited.Headers)
+	if edited.HTTP != nil && original.HTTP != nil && edited.HTTP.Proxy == shown.HTTP.Proxy {
+		settings := *edited.HTTP
+		settings.Proxy = original.HTTP.Proxy
+		edited.HTTP = &settings
+	}
+	if edited.Alert != nil && original.Alert != nil {
+		policy := *edited.Alert
+		policy.Channels = make([]types.AlertChannel, len(edited.Alert.Channels))
+		for i, channel := range edited.Alert.Channels {
+			if i < len(original.Alert.Channels) {
+				orig, masked := original.Alert.Channels[i], shown.Alert.Channels[i]
+				if channel.URL == masked.URL {
+					channel.URL = orig.URL
+				}
+				if channel.Password == masked.Password {
+					channel.Password = orig.Password
+				}
+				channel.Headers = restoreHeaders(orig.Headers, masked.Headers, channel.Headers)
+			}
+			policy.Channels[i] = channel
+		}
+		edited.Alert = &policy
+	}
+	return edited
+}
+
+// restoreHeaders returns a copy of the edited headers with the values still
+// shown as redacted set back to the original ones
+func restoreHeaders(original, shown, edited map[string]string) map[string]string {
+	if edited == nil {
+		return nil
+	}
+	restored := make(map[string]string, len(edited))
+	for name, value := range edited {
+		if orig, ok := original[name]; ok && value == shown[name] {
+			value = orig
+		}
+		restored[name] = value
+	}
+	return restored
+}
+
+// AlertChannels returns a copy of the channels with their URLs, headers and
+// passwords redacted. Webhook URLs usually carry their token in the path, so
+// everything after the host is masked.
+func (r *Redactor) AlertChannels(channels []types.AlertChannel) []types.AlertChannel {
+	r = r.rules()
+	if r.disabled || channels == nil {
+		return channels
+	}
+
+	redacted := make([]types.AlertChannel, len(channels))
+	for i, channel := range channels {
+		if u, err := url.Parse(channel.URL); err == nil && u.Host != "" && (u.Path != "" || u.RawQuery != "" || u.User != nil) {
+			channel.URL = u.Scheme + "://" + u.Host + "/" + Mask
+		}
+		channel.Headers = r.Headers(channel.Headers)
+		if channel.Password != "" {
+			channel.Password = Mask
+		}
+		redacted[i] = channel
+	}
+	return redacted
+}
+
+// Execution returns a copy of the execution with its error and assertion
+// failures redacted
+func (r *Redactor) Execution(exec types.Execution) types.Execution {
+	exec.Error = r.String(exec.Error)
+	if exec.Failures != nil {
+		failures := make([]types.AssertionFailure, len(exec.Failures))
+		for i, failure := range exec.Failures {
+			failure.Expected = r.String(failure.Expected)
+			failure.Actual = r.String(failure.Actual)
+			failures[i] = failure
+		}
+		exec.Failures = failures
+	}
+	return exec
+}
+
+// Error returns err with a redacted message. The original error stays
+// available to errors.Is and errors.As.
+func (r *Redactor) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	message := r.String(err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{message: message, err: err}
+}
+
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// replacePattern masks every match of re, or only its first group if it has one
+func replacePattern(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllString(s, Mask)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(s, -1) {
+		start, end := match[2], match[3]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(Mask)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
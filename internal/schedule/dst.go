@@ -0,0 +1,96 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// allHours is the hour field of a schedule that fires in every hour of the day
+const allHours = 1<<24 - 1
+
+// zonedSchedule adjusts a cron schedule for daylight saving time changes.
+//
+// Schedules that fire in every hour, such as "0 */15 * * * *", follow the
+// wall clock: runs in a skipped hour do not happen and runs in a repeated
+// hour happen twice, so the time between runs stays the same.
+//
+// Schedules for specific hours, such as "0 30 2 * * *", fire once a day as
+// expected: a run that falls into a skipped hour happens when the clock
+// jumps forward, and a run in a repeated hour only happens the first time.
+type zonedSchedule struct {
+	spec *cron.SpecSchedule
+}
+
+func (s *zonedSchedule) Next(t time.Time) time.Time {
+	if s.spec.Hour&allHours == allHours {
+		return s.spec.Next(t)
+	}
+
+	// Find matching wall clock times on a clock without DST changes and
+	// map each to the moment it is reached in the job's time zone
+	loc := s.spec.Location
+	wallSpec := *s.spec
+	wallSpec.Location = time.UTC
+
+	wall := wallClock(t.In(loc))
+	for {
+		wall = wallSpec.Next(wall)
+		if wall.IsZero() {
+			return wall
+		}
+		if next, ok := firstReached(wall, loc); ok && next.After(t) {
+			return next
+		}
+	}
+}
+
+// wallClock returns the clock reading of t as a UTC time
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// firstReached returns the first moment a clock in loc shows the wall time.
+// A time skipped by the clock jumping forward is reached when it jumps.
+func firstReached(wall time.Time, loc *time.Location) (time.Time, bool) {
+	// A wall time lies within 14 hours of the same reading in UTC, and DST
+	// changes are far enough apart that at most one affects it
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	// With the clock turned back the earlier offset reaches the time first
+	offsets := []int{before, after}
+	if after > before {
+		offsets = []int{after, before}
+	}
+	for _, offset := range offsets {
+		moment := wall.Add(-time.Duration(offset) * time.Second)
+		if _, actual := moment.In(loc).Zone(); actual == offset {
+			return moment.In(loc), true
+		}
+	}
+
+	if after <= before {
+		return time.Time{}, false
+	}
+
+	// The clock jumped over the wall time, between the moments it would have
+	// been reached with the new and the old offset
+	from := wall.Add(-time.Duration(after) * time.Second)
+	to := wall.Add(-time.Duration(before) * time.Second)
+	return transition(from, to, loc), true
+}
+
+// transition finds the first second in (from, to] with a different UTC offset than from
+func transition(from, to time.Time, loc *time.Location) time.Time {
+	_, offset := from.In(loc).Zone()
+	for to.Sub(from) > time.Second {
+		mid := from.Add(to.Sub(from) / 2).Truncate(time.Second)
+		if _, midOffset := mid.In(loc).Zone(); midOffset == offset {
+			from = mid
+		} else {
+			to = mid
+		}
+	}
+	return to.In(loc)
+}
@@ -0,0 +1,146 @@
+package schedule
+
+import (
+	"strings"
+	"tempo/internal/types"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// DST changes in 2026:
+//
+//	America/New_York  Mar 8  02:00 EST -> 03:00 EDT, Nov 1  02:00 EDT -> 01:00 EST
+//	Europe/London     Mar 29 01:00 GMT -> 02:00 BST, Oct 25 02:00 BST -> 01:00 GMT
+func TestZonedScheduleDST(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		expr string
+		from string
+		want []string
+	}{
+		{
+			name: "new york daily run in the skipped hour fires at the jump",
+			zone: "America/New_York",
+			expr: "0 30 2 * * *",
+			from: "2026-03-07T12:00:00-05:00",
+			want: []string{"2026-03-08T03:00:00-04:00", "2026-03-09T02:30:00-04:00"},
+		},
+		{
+			name: "new york daily run at the start of the skipped hour",
+			zone: "America/New_York",
+			expr: "0 0 2 * * *",
+			from: "2026-03-07T12:00:00-05:00",
+			want: []string{"2026-03-08T03:00:00-04:00", "2026-03-09T02:00:00-04:00"},
+		},
+		{
+			name: "new york daily run in the repeated hour fires once",
+			zone: "America/New_York",
+			expr: "0 30 1 * * *",
+			from: "2026-10-31T12:00:00-04:00",
+			want: []string{"2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		},
+		{
+			name: "new york daily run after the repeated hour",
+			zone: "America/New_York",
+			expr: "0 0 3 * * *",
+			from: "2026-10-31T12:00:00-04:00",
+			want: []string{"2026-11-01T03:00:00-05:00", "2026-11-02T03:00:00-05:00"},
+		},
+		{
+			name: "london daily run in the skipped hour fires at the jump",
+			zone: "Europe/London",
+			expr: "0 30 1 * * *",
+			from: "2026-03-28T12:00:00Z",
+			want: []string{"2026-03-29T02:00:00+01:00", "2026-03-30T01:30:00+01:00"},
+		},
+		{
+			name: "london daily run in the repeated hour fires once",
+			zone: "Europe/London",
+			expr: "0 30 1 * * *",
+			from: "2026-10-24T12:00:00+01:00",
+			want: []string{"2026-10-25T01:30:00+01:00", "2026-10-26T01:30:00Z"},
+		},
+		{
+			name: "london weekly run in the repeated hour fires once",
+			zone: "Europe/London",
+			expr: "0 15 1 * * SUN",
+			from: "2026-10-24T12:00:00+01:00",
+			want: []string{"2026-10-25T01:15:00+01:00", "2026-11-01T01:15:00Z"},
+		},
+		{
+			name: "new york hourly runs skip the missing hour",
+			zone: "America/New_York",
+			expr: "0 0 * * * *",
+			from: "2026-03-08T00:30:00-05:00",
+			want: []string{"2026-03-08T01:00:00-05:00", "2026-03-08T03:00:00-04:00", "2026-03-08T04:00:00-04:00"},
+		},
+		{
+			name: "new york hourly runs repeat in the repeated hour",
+			zone: "America/New_York",
+			expr: "0 0 * * * *",
+			from: "2026-11-01T00:30:00-04:00",
+			want: []string{"2026-11-01T01:00:00-04:00", "2026-11-01T01:00:00-05:00", "2026-11-01T02:00:00-05:00"},
+		},
+		{
+			name: "london every 20 minutes across the skipped hour",
+			zone: "Europe/London",
+			expr: "0 */20 * * * *",
+			from: "2026-03-29T00:30:00Z",
+			want: []string{"2026-03-29T00:40:00Z", "2026-03-29T02:00:00+01:00", "2026-03-29T02:20:00+01:00"},
+		},
+		{
+			name: "london every 20 minutes across the repeated hour",
+			zone: "Europe/London",
+			expr: "0 */20 * * * *",
+			from: "2026-10-25T00:50:00+01:00",
+			want: []string{
+				"2026-10-25T01:00:00+01:00", "2026-10-25T01:20:00+01:00", "2026-10-25T01:40:00+01:00",
+				"2026-10-25T01:00:00Z", "2026-10-25T01:20:00Z", "2026-10-25T01:40:00Z",
+				"2026-10-25T02:00:00Z",
+			},
+		},
+		{
+			name: "new york every 15 minutes across the repeated hour keeps the spacing",
+			zone: "America/New_York",
+			expr: "0 */15 * * * *",
+			from: "2026-11-01T01:40:00-04:00",
+			want: []string{"2026-11-01T01:45:00-04:00", "2026-11-01T01:00:00-05:00", "2026-11-01T01:15:00-05:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := Parse(types.Job{CronExpr: tt.expr, TimeZone: tt.zone})
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.expr, err)
+			}
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			from, err := time.Parse(time.RFC3339, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := from
+			for i, want := range tt.want {
+				next = sched.Next(next)
+				if got := next.In(loc).Format(time.RFC3339); got != want {
+					t.Fatalf("run %d = %s, want %s", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidTimeZone(t *testing.T) {
+	for _, zone := range []string{"Mars/Olympus_Mons", "EST5EDT4", "../../etc/passwd"} {
+		_, err := Parse(types.Job{CronExpr: "0 0 * * * *", TimeZone: zone})
+		if err == nil || !strings.Contains(err.Error(), "invalid time zone") {
+			t.Errorf("Parse() with time zone %q = %v, want an invalid time zone error", zone, err)
+		}
+	}
+}
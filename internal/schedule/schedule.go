@@ -0,0 +1,108 @@
+package schedule
+
+import (
+	"fmt"
+	"tempo/internal/types"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// parser accepts six-field cron expressions with seconds and descriptors such as "@daily"
+var parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// MinInterval is the shortest interval of an interval schedule
+const MinInterval = time.Second
+
+// Parse returns the schedule a job fires on. Cron expressions are evaluated
+// in the job's time zone or the scheduler's local time zone if it has none.
+func Parse(job types.Job) (cron.Schedule, error) {
+	kinds := 0
+	if job.CronExpr != "" {
+		kinds++
+	}
+	if job.At != nil {
+		kinds++
+	}
+	if job.Every != 0 {
+		kinds++
+	}
+	if kinds != 1 {
+		return nil, fmt.Errorf("a job needs exactly one of a cron schedule, a one-shot time (at) or an interval (every)")
+	}
+
+	loc, err := Location(job.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.ScheduleKind() {
+	case types.ScheduleAt:
+		return Once(*job.At), nil
+	case types.ScheduleEvery:
+		if job.Every < types.Duration(MinInterval) {
+			return nil, fmt.Errorf("interval must be at least %s", MinInterval)
+		}
+		if job.Anchor == nil {
+			return nil, fmt.Errorf("interval schedules need an anchor time")
+		}
+		return &intervalSchedule{anchor: *job.Anchor, every: time.Duration(job.Every)}, nil
+	}
+
+	parsed, err := parser.Parse(job.CronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %v", job.CronExpr, err)
+	}
+
+	spec, ok := parsed.(*cron.SpecSchedule)
+	if !ok {
+		// "@every" schedules are fixed intervals, unaffected by time zones
+		return parsed, nil
+	}
+	spec.Location = loc
+	return &zonedSchedule{spec: spec}, nil
+}
+
+// Once returns a schedule that fires at the given time only
+func Once(at time.Time) cron.Schedule {
+	return onceSchedule{at: at}
+}
+
+type onceSchedule struct {
+	at time.Time
+}
+
+func (s onceSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.at) {
+		return s.at
+	}
+	return time.Time{}
+}
+
+// intervalSchedule fires at anchor + n*every, so restarts keep the phase
+type intervalSchedule struct {
+	anchor time.Time
+	every  time.Duration
+}
+
+func (s *intervalSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.anchor) {
+		return s.anchor
+	}
+	periods := t.Sub(s.anchor)/s.every + 1
+	return s.anchor.Add(periods * s.every)
+}
+
+// Location loads an IANA time zone such as "Europe/Berlin". An empty name is
+// the scheduler's local time zone.
+func Location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q. Use an IANA name like 'Europe/Berlin' or 'UTC'", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// EnvKey holds the secret key passphrase itself
+	EnvKey = "TEMPO_SECRET_KEY"
+	// EnvKeyFile names a file whose contents are the secret key
+	EnvKeyFile = "TEMPO_SECRET_KEY_FILE"
+	// KeyFileName is the key file used when EnvKeyFile is not set
+	KeyFileName = "secret.key"
+)
+
+// LoadKey returns the secret key configured for the data directory, looking
+// at TEMPO_SECRET_KEY, then the file named by TEMPO_SECRET_KEY_FILE, then
+// secret.key in the data directory. It reports false if none is configured.
+func LoadKey(dataDir string) (string, bool, error) {
+	if key := os.Getenv(EnvKey); key != "" {
+		return key, true, nil
+	}
+
+	path := os.Getenv(EnvKeyFile)
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(dataDir, KeyFileName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read secret key file: %v", err)
+	}
+
+	key := strings.TrimRight(string(data), "\r\n")
+	if key == "" {
+		return "", false, fmt.Errorf("secret key file %s is empty", path)
+	}
+	return key, true, nil
+}
@@ -0,0 +1,321 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"tempo/internal/storage"
+	"time"
+)
+
+// FileName is the encrypted secrets file inside the data directory
+const FileName = "secrets.json"
+
+const (
+	fileVersion   = 1
+	kdfIterations = 600000
+	keyLength     = 32 // AES-256
+	saltLength    = 16
+
+	// checkValue is encrypted with the key so a wrong passphrase is detected
+	// before anything is decrypted or written
+	checkValue = "tempo"
+	checkName  = "\x00check"
+)
+
+var (
+	// ErrNotFound is returned for secrets that do not exist
+	ErrNotFound = errors.New("secret not found")
+	// ErrLocked is returned when a value is read or written before Unlock
+	ErrLocked = errors.New("secrets store is locked, no secret key configured")
+	// ErrWrongKey is returned by Unlock when the passphrase does not match the store
+	ErrWrongKey = errors.New("wrong secret key")
+)
+
+// Info describes a stored secret without its value
+type Info struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store keeps named secrets encrypted with AES-256-GCM in secrets.json.
+// The key is derived from a passphrase with PBKDF2-SHA256 and a random salt
+// kept in the file. Names are stored in plain text so secrets can be listed
+// without the key; values can only be read or written after Unlock.
+type Store struct {
+	path  string
+	mutex sync.Mutex
+	key   []byte
+	file  secretsFile
+}
+
+type secretsFile struct {
+	Version    int              `json:"version"`
+	Salt       []byte           `json:"salt"`
+	Iterations int              `json:"iterations"`
+	Check      []byte           `json:"check"`
+	Secrets    map[string]entry `json:"secrets"`
+}
+
+type entry struct {
+	Value     []byte    `json:"value"` // nonce followed by the sealed value
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Open loads the secrets store in the data directory. A missing file yields
+// an empty store that is created on the first Set.
+func Open(dataDir string) (*Store, error) {
+	dataDir, err := storage.DataDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{path: filepath.Join(dataDir, FileName)}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Path returns the location of the secrets file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Exists reports whether the secrets file has been created
+func (s *Store) Exists() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Salt != nil
+}
+
+// Unlock derives the key from the passphrase. For an existing store the
+// passphrase is checked against it; a new store adopts it.
+func (s *Store) Unlock(passphrase string) error {
+	if passphrase == "" {
+		return errors.New("secret key cannot be empty")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file.Salt == nil {
+		salt := make([]byte, saltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		s.file.Salt = salt
+		s.file.Iterations = kdfIterations
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, s.file.Salt, s.file.Iterations, keyLength)
+	if err != nil {
+		return fmt.Errorf("failed to derive secret key: %v", err)
+	}
+
+	if s.file.Check != nil {
+		check, err := open(key, checkName, s.file.Check)
+		if err != nil || string(check) != checkValue {
+			return ErrWrongKey
+		}
+	} else {
+		if s.file.Check, err = seal(key, checkName, []byte(checkValue)); err != nil {
+			return err
+		}
+	}
+
+	s.key = key
+	return nil
+}
+
+// Get decrypts the named secret. The file is re-read first, so secrets set
+// by other processes are picked up.
+func (s *Store) Get(name string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.key == nil {
+		return "", ErrLocked
+	}
+	if err := s.load(); err != nil {
+		return "", err
+	}
+
+	entry, exists := s.file.Secrets[name]
+	if !exists {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+
+	value, err := open(s.key, name, entry.Value)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %q: %v", name, err)
+	}
+	return string(value), nil
+}
+
+// Secret returns the named secret's value for {{ secret "name" }} templates
+func (s *Store) Secret(name string) (string, error) {
+	return s.Get(name)
+}
+
+// Set encrypts and stores a secret, replacing any previous value
+func (s *Store) Set(name, value string) error {
+	if name == "" {
+		return errors.New("secret name cannot be empty")
+	}
+
+	return s.update(func() error {
+		sealed, err := seal(s.key, name, []byte(value))
+		if err != nil {
+			return err
+		}
+		s.file.Secrets[name] = entry{Value: sealed, UpdatedAt: time.Now().UTC()}
+		return nil
+	})
+}
+
+// Remove deletes a secret
+func (s *Store) Remove(name string) error {
+	return s.update(func() error {
+		if _, exists := s.file.Secrets[name]; !exists {
+			return fmt.Errorf("%w: %q", ErrNotFound, name)
+		}
+		delete(s.file.Secrets, name)
+		return nil
+	})
+}
+
+// List returns the stored secrets sorted by name. It does not need the key.
+func (s *Store) List() ([]Info, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	infos := make([]Info, 0, len(s.file.Secrets))
+	for name, entry := range s.file.Secrets {
+		infos = append(infos, Info{Name: name, UpdatedAt: entry.UpdatedAt})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// update applies a change while holding the secrets file lock, re-reading the
+// file first so concurrent changes are kept
+func (s *Store) update(change func() error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.key == nil {
+		return ErrLocked
+	}
+
+	unlock, err := storage.LockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock secrets file: %v", err)
+	}
+	defer unlock()
+
+	// Keep the salt and check a new store was unlocked with
+	salt, iterations, check := s.file.Salt, s.file.Iterations, s.file.Check
+	if err := s.load(); err != nil {
+		return err
+	}
+	if s.file.Salt == nil {
+		s.file.Salt, s.file.Iterations, s.file.Check = salt, iterations, check
+	} else if string(s.file.Salt) != string(salt) {
+		return errors.New("secrets file was replaced, unlock it again")
+	}
+
+	if err := change(); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.file = secretsFile{Version: fileVersion, Secrets: make(map[string]entry)}
+			return nil
+		}
+		return fmt.Errorf("failed to read secrets: %v", err)
+	}
+
+	var file secretsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse secrets: %v", err)
+	}
+	if file.Version != fileVersion {
+		return fmt.Errorf("unsupported secrets file version %d", file.Version)
+	}
+	if file.Secrets == nil {
+		file.Secrets = make(map[string]entry)
+	}
+
+	s.file = file
+	return nil
+}
+
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %v", err)
+	}
+
+	// Only the owner may read the file, even though values are encrypted
+	if err := storage.WriteFileAtomic(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %v", err)
+	}
+
+	return nil
+}
+
+// seal encrypts a value, binding it to the secret's name so ciphertexts
+// cannot be swapped between names
+func seal(key []byte, name string, value []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, value, []byte(name)), nil
+}
+
+func open(key []byte, name string, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(name))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"tempo/internal/types"
+	"time"
+)
+
+// Connection pool tuning of the shared transports. Jobs often call the same
+// hosts, so far more idle connections per host are kept than Go's default of 2.
+const (
+	maxIdleConns        = 1024
+	maxIdleConnsPerHost = 64
+	idleConnTimeout     = 90 * time.Second
+)
+
+/*
+* Transports shares HTTP transports, and with them the connection pool of
+* every host, between jobs whose TLS, proxy and protocol settings match
+* Clients only differ in timeouts and redirects, so they are cached too
+ */
+type Transports struct {
+	maxConnsPerHost int
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport // by transport settings
+	clients    map[string]*http.Client    // by all settings
+}
+
+// transportKey holds the settings that need a separate transport
+type transportKey struct {
+	CACert, ClientCert, ClientKey string
+	Insecure                      bool
+	Proxy, Version                string
+}
+
+// defaultTransports serves executions made outside of a scheduler
+var defaultTransports = NewTransports(0)
+
+/*
+* NewTransports creates a transport pool
+* maxConnsPerHost limits the connections to a single host, zero means unlimited
+ */
+func NewTransports(maxConnsPerHost int) *Transports {
+	return &Transports{
+		maxConnsPerHost: maxConnsPerHost,
+		transports:      make(map[string]*http.Transport),
+		clients:         make(map[string]*http.Client),
+	}
+}
+
+/*
+* Client returns the shared client for the given settings, creating it if needed
+ */
+func (t *Transports) Client(settings *types.HTTPConfig) (*http.Client, error) {
+	clientID, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if client, ok := t.clients[string(clientID)]; ok {
+		return client, nil
+	}
+
+	key := transportKey{}
+	if settings != nil {
+		key = transportKey{settings.CACert, settings.ClientCert, settings.ClientKey, settings.SkipVerify(), settings.Proxy, settings.Version}
+	}
+	transportID, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, ok := t.transports[string(transportID)]
+	if !ok {
+		if transport, err = newTransport(settings); err != nil {
+			return nil, err
+		}
+		transport.MaxConnsPerHost = t.maxConnsPerHost
+		t.transports[string(transportID)] = transport
+	}
+
+	client := newClient(transport, settings)
+	t.clients[string(clientID)] = client
+	return client, nil
+}
+
+/*
+* CloseIdle closes the idle connections of every transport
+ */
+func (t *Transports) CloseIdle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+/*
+* NewHTTPClient creates a client with the given settings, nil for the defaults
+* It reads the CA bundle and client certificate, so it fails if they are unusable
+ */
+func NewHTTPClient(settings *types.HTTPConfig) (*http.Client, error) {
+	transport, err := newTransport(settings)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(transport, settings), nil
+}
+
+/*
+* newTransport creates a transport with the TLS, proxy and protocol settings
+ */
+func newTransport(settings *types.HTTPConfig) (*http.Transport, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+
+	tlsConfig := &tls.Config{}
+	if settings != nil {
+		if settings.CACert != "" {
+			pem, err := os.ReadFile(settings.CACert)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA bundle %s", settings.CACert)
+			}
+			tlsConfig.RootCAs = roots
+		}
+
+		if settings.ClientCert != "" {
+			// The key may be in the certificate file
+			keyFile := settings.ClientKey
+			if keyFile == "" {
+				keyFile = settings.ClientCert
+			}
+			cert, err := tls.LoadX509KeyPair(settings.ClientCert, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %v", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		tlsConfig.InsecureSkipVerify = settings.SkipVerify()
+
+		switch settings.Proxy {
+		case "":
+			// Keep the proxy from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+		case types.ProxyNone:
+			transport.Proxy = nil
+		default:
+			proxy, err := url.Parse(settings.Proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy URL: %v", err)
+			}
+			transport.Proxy = http.ProxyURL(proxy)
+		}
+
+		protocols := new(http.Protocols)
+		switch settings.Version {
+		case types.HTTPVersion1:
+			protocols.SetHTTP1(true)
+			transport.Protocols = protocols
+		case types.HTTPVersion2:
+			// Plain http:// URLs use HTTP/2 with prior knowledge
+			protocols.SetHTTP2(true)
+			protocols.SetUnencryptedHTTP2(true)
+			transport.Protocols = protocols
+		}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+/*
+* newClient creates a client with the timeout and redirect settings
+ */
+func newClient(transport *http.Transport, settings *types.HTTPConfig) *http.Client {
+	redirects := settings.Redirects()
+	return &http.Client{
+		Transport: transport,
+		Timeout:   settings.RequestTimeout(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if redirects == 0 {
+				// Return the redirect response itself
+				return http.ErrUseLastResponse
+			}
+			if len(via) > redirects {
+				return fmt.Errorf("stopped after %d redirects", redirects)
+			}
+			return nil
+		},
+	}
+}
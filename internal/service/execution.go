@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"tempo/internal/assertion"
+	"tempo/internal/logging"
+	"tempo/internal/types"
+	"time"
+)
+
+/*
+* Recorder persists execution records
+* It is satisfied by every storage backend
+ */
+type Recorder interface {
+	RecordExecution(exec types.Execution) error
+}
+
+/*
+* Execute calls the job's webhook once, checks the job's assertions and describes the attempt
+* It returns the execution record together with the webhook or assertion error, if any
+* Cancelling the context aborts the request
+ */
+func Execute(ctx context.Context, job types.Job, scheduledAt time.Time, trigger string) (types.Execution, error) {
+	return execute(ctx, defaultTransports, job, scheduledAt, trigger)
+}
+
+/*
+* execute makes an attempt with clients from the given transport pool
+ */
+func execute(ctx context.Context, transports *Transports, job types.Job, scheduledAt time.Time, trigger string) (types.Execution, error) {
+	exec := types.Execution{
+		ID:          newExecutionID(),
+		JobID:       job.ID,
+		Trigger:     trigger,
+		Attempt:     1,
+		ScheduledAt: scheduledAt,
+		StartedAt:   time.Now(),
+	}
+
+	resp, err := callWebhook(ctx, transports, job)
+
+	exec.FinishedAt = time.Now()
+	exec.LatencyMs = exec.FinishedAt.Sub(exec.StartedAt).Milliseconds()
+	exec.Status = types.StatusSuccess
+	if resp != nil {
+		exec.StatusCode = resp.StatusCode
+		exec.ResponseSize = resp.Size
+	}
+	if err == nil && job.Assert != nil {
+		exec.Failures = assertion.Check(job.Assert, assertion.Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       resp.Body,
+			Truncated:  resp.Truncated,
+			Latency:    exec.FinishedAt.Sub(exec.StartedAt),
+		})
+		if len(exec.Failures) > 0 {
+			err = &assertion.Error{Failures: exec.Failures}
+		}
+	}
+	if err != nil {
+		exec.Status = types.StatusFailure
+		exec.Error = err.Error()
+	}
+
+	return exec, err
+}
+
+/*
+* failedExecution records a run that failed before any request was sent
+ */
+func failedExecution(job types.Job, scheduledAt time.Time, trigger string, err error) types.Execution {
+	now := time.Now()
+	return types.Execution{
+		ID:          newExecutionID(),
+		JobID:       job.ID,
+		Trigger:     trigger,
+		Attempt:     1,
+		ScheduledAt: scheduledAt,
+		StartedAt:   now,
+		FinishedAt:  now,
+		Status:      types.StatusFailure,
+		Error:       err.Error(),
+	}
+}
+
+/*
+* newExecutionID returns a random identifier for an execution record
+ */
+func newExecutionID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+/*
+* executionAttrs are the log fields of an execution
+* Its error must already be redacted
+ */
+func executionAttrs(exec types.Execution) []any {
+	attrs := []any{
+		logging.KeyJobID, exec.JobID,
+		logging.KeyExecutionID, exec.ID,
+		logging.KeyAttempt, exec.Attempt,
+		logging.KeyStatus, string(exec.Status),
+		logging.KeyDurationMs, exec.LatencyMs,
+	}
+	if exec.RunID != "" {
+		attrs = append(attrs, "run_id", exec.RunID)
+	}
+	if exec.StatusCode != 0 {
+		attrs = append(attrs, "status_code", exec.StatusCode)
+	}
+	if exec.Error != "" {
+		attrs = append(attrs, logging.KeyError, exec.Error)
+	}
+	return attrs
+}
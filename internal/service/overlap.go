@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"tempo/internal/types"
+	"time"
+)
+
+// errSuperseded is the cancellation cause of a run cancelled by a newer run
+var errSuperseded = errors.New("cancelled by a newer run")
+
+/*
+* overlap tracks the scheduled run of a job in progress and the runs waiting for it
+ */
+type overlap struct {
+	running bool
+	cancel  context.CancelCauseFunc // cancels the run in progress
+	done    chan struct{}           // closed when the run in progress ends
+	queue   []chan struct{}         // runs waiting under the queue policy, oldest first
+}
+
+/*
+* runScheduled starts a scheduled run of the job, applying the job's overlap
+* policy if its previous scheduled run is still in progress
+* Manual runs are not subject to the policy
+ */
+func (s *Scheduler) runScheduled(job types.Job, scheduledAt time.Time) {
+	if job.OverlapPolicy() == types.OverlapAllow {
+		s.runJob(context.Background(), job, scheduledAt, types.TriggerSchedule)
+		return
+	}
+
+	ctx, release, ok := s.acquire(job, scheduledAt)
+	if !ok {
+		return
+	}
+	defer release()
+
+	s.runJob(ctx, job, scheduledAt, types.TriggerSchedule)
+}
+
+/*
+* acquire waits until the job may run according to its overlap policy
+* It returns the context of the run and a function to call when it ends,
+* or false if the run is skipped
+ */
+func (s *Scheduler) acquire(job types.Job, scheduledAt time.Time) (context.Context, func(), bool) {
+	s.mu.Lock()
+	for {
+		o := s.overlapLocked(job.ID)
+		if !o.running {
+			ctx, release := s.startLocked(job.ID, o)
+			s.mu.Unlock()
+			return ctx, release, true
+		}
+
+		switch job.OverlapPolicy() {
+		case types.OverlapSkip:
+			s.mu.Unlock()
+			s.skip(job, scheduledAt, "the previous run is still in progress")
+			return nil, nil, false
+
+		case types.OverlapQueue:
+			if len(o.queue) >= job.MaxQueued() {
+				s.mu.Unlock()
+				s.skip(job, scheduledAt, fmt.Sprintf("%d runs are already waiting for the previous run", len(o.queue)))
+				return nil, nil, false
+			}
+			turn := make(chan struct{})
+			o.queue = append(o.queue, turn)
+			s.mu.Unlock()
+
+			select {
+			case <-turn:
+				// The run that ended handed its slot over
+				s.mu.Lock()
+				ctx, release := s.startLocked(job.ID, o)
+				s.mu.Unlock()
+				return ctx, release, true
+			case <-s.ctx.Done():
+				s.mu.Lock()
+				if i := slices.Index(o.queue, turn); i >= 0 {
+					o.queue = slices.Delete(o.queue, i, i+1)
+				} else {
+					// The slot was handed over while stopping, pass it on
+					s.handOffLocked(job.ID, o)
+				}
+				s.mu.Unlock()
+				return nil, nil, false
+			}
+
+		case types.OverlapCancel:
+			o.cancel(errSuperseded)
+			done := o.done
+			s.mu.Unlock()
+			<-done
+			s.mu.Lock()
+		}
+	}
+}
+
+/*
+* overlapLocked returns the overlap state of a job, creating it if needed
+* The caller must hold s.mu
+ */
+func (s *Scheduler) overlapLocked(jobID string) *overlap {
+	o, ok := s.overlaps[jobID]
+	if !ok {
+		o = &overlap{}
+		s.overlaps[jobID] = o
+	}
+	return o
+}
+
+/*
+* startLocked marks a run of the job as in progress
+* The caller must hold s.mu
+ */
+func (s *Scheduler) startLocked(jobID string, o *overlap) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done := make(chan struct{})
+	o.running = true
+	o.cancel = cancel
+	o.done = done
+
+	release := func() {
+		cancel(nil)
+		s.mu.Lock()
+		close(done)
+		s.handOffLocked(jobID, o)
+		s.mu.Unlock()
+	}
+	return ctx, release
+}
+
+/*
+* handOffLocked passes the job's slot to the oldest waiting run, if any
+* The caller must hold s.mu
+ */
+func (s *Scheduler) handOffLocked(jobID string, o *overlap) {
+	if len(o.queue) > 0 {
+		close(o.queue[0])
+		o.queue = o.queue[1:]
+		return
+	}
+	o.running = false
+	if s.overlaps[jobID] == o {
+		delete(s.overlaps, jobID)
+	}
+}
+
+/*
+* skip records a scheduled run that did not start
+ */
+func (s *Scheduler) skip(job types.Job, scheduledAt time.Time, reason string) {
+	now := time.Now()
+	exec := types.Execution{
+		ID:          newExecutionID(),
+		JobID:       job.ID,
+		Trigger:     types.TriggerSchedule,
+		Attempt:     1,
+		ScheduledAt: scheduledAt,
+		StartedAt:   now,
+		FinishedAt:  now,
+		Status:      types.StatusSkipped,
+		Error:       reason,
+	}
+	s.record(exec, false)
+	s.publish(executionEvent(exec, 1))
+	s.log.Warn("Execution skipped", executionAttrs(exec)...)
+}
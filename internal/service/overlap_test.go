@@ -0,0 +1,229 @@
+package service
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"tempo/internal/types"
+	"testing"
+	"time"
+)
+
+// memoryHistory records executions in memory
+type memoryHistory struct {
+	mu    sync.Mutex
+	execs []types.Execution
+}
+
+func (h *memoryHistory) RecordExecution(exec types.Execution) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.execs = append(h.execs, exec)
+	return nil
+}
+
+func (h *memoryHistory) statuses() []types.ExecutionStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var statuses []types.ExecutionStatus
+	for _, exec := range h.execs {
+		statuses = append(statuses, exec.Status)
+	}
+	return statuses
+}
+
+// blockingServer answers each request once it is released, or when the
+// request is cancelled. It reports every request it starts on started.
+type blockingServer struct {
+	*httptest.Server
+	started chan struct{}
+	release chan struct{}
+
+	active    atomic.Int32
+	maxActive atomic.Int32
+}
+
+func newBlockingServer(t *testing.T) *blockingServer {
+	s := &blockingServer{started: make(chan struct{}, 10), release: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		active := s.active.Add(1)
+		defer s.active.Add(-1)
+		for {
+			most := s.maxActive.Load()
+			if active <= most || s.maxActive.CompareAndSwap(most, active) {
+				break
+			}
+		}
+
+		s.started <- struct{}{}
+		select {
+		case <-s.release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// waitStarted waits for the server to receive a request
+func (s *blockingServer) waitStarted(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a request")
+	}
+}
+
+func newTestScheduler(t *testing.T, opts ...Option) (*Scheduler, *memoryHistory) {
+	history := &memoryHistory{}
+	opts = append([]Option{WithHistory(history), WithLogger(slog.New(slog.DiscardHandler))}, opts...)
+	s := NewScheduler(opts...)
+	t.Cleanup(s.Stop)
+	return s, history
+}
+
+// runAsync starts a scheduled run and returns a channel closed when it ends
+func runAsync(s *Scheduler, job types.Job) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.runScheduled(job, time.Now())
+	}()
+	return done
+}
+
+func waitDone(t *testing.T, done chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a run to end")
+	}
+}
+
+// waitQueued waits until the given number of runs of the job wait for the previous one
+func waitQueued(t *testing.T, s *Scheduler, jobID string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		o, ok := s.overlaps[jobID]
+		queued := ok && len(o.queue) == n
+		s.mu.Unlock()
+		if queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d queued runs", n)
+}
+
+func TestOverlapSkip(t *testing.T) {
+	server := newBlockingServer(t)
+	s, history := newTestScheduler(t)
+	job := types.Job{ID: "skip", Method: "GET", URL: server.URL, Overlap: types.OverlapSkip}
+
+	first := runAsync(s, job)
+	server.waitStarted(t)
+
+	// The second run returns at once without sending a request
+	s.runScheduled(job, time.Now())
+	if got := history.statuses(); !slices.Equal(got, []types.ExecutionStatus{types.StatusSkipped}) {
+		t.Fatalf("statuses while the first run is in progress = %v, want one skipped run", got)
+	}
+
+	close(server.release)
+	waitDone(t, first)
+
+	// With the previous run over the next one runs again
+	s.runScheduled(job, time.Now())
+	want := []types.ExecutionStatus{types.StatusSkipped, types.StatusSuccess, types.StatusSuccess}
+	if got := history.statuses(); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestOverlapQueue(t *testing.T) {
+	server := newBlockingServer(t)
+	s, history := newTestScheduler(t)
+	job := types.Job{ID: "queue", Method: "GET", URL: server.URL, Overlap: types.OverlapQueue, QueueLimit: 1}
+
+	first := runAsync(s, job)
+	server.waitStarted(t)
+	second := runAsync(s, job)
+	waitQueued(t, s, job.ID, 1)
+
+	// The queue is full, so a third run is skipped
+	s.runScheduled(job, time.Now())
+	if got := history.statuses(); !slices.Equal(got, []types.ExecutionStatus{types.StatusSkipped}) {
+		t.Fatalf("statuses with a full queue = %v, want one skipped run", got)
+	}
+
+	// The queued run starts once the first one ends
+	server.release <- struct{}{}
+	waitDone(t, first)
+	server.waitStarted(t)
+	server.release <- struct{}{}
+	waitDone(t, second)
+
+	want := []types.ExecutionStatus{types.StatusSkipped, types.StatusSuccess, types.StatusSuccess}
+	if got := history.statuses(); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if most := server.maxActive.Load(); most != 1 {
+		t.Errorf("%d requests ran at the same time, want 1", most)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.overlaps[job.ID]; ok {
+		t.Errorf("overlap state of %q kept after its runs ended", job.ID)
+	}
+}
+
+func TestOverlapCancel(t *testing.T) {
+	server := newBlockingServer(t)
+	s, history := newTestScheduler(t)
+	job := types.Job{ID: "cancel", Method: "GET", URL: server.URL, Overlap: types.OverlapCancel}
+
+	first := runAsync(s, job)
+	server.waitStarted(t)
+
+	// The new run replaces the one in progress
+	second := runAsync(s, job)
+	waitDone(t, first)
+	server.waitStarted(t)
+	close(server.release)
+	waitDone(t, second)
+
+	want := []types.ExecutionStatus{types.StatusCancelled, types.StatusSuccess}
+	if got := history.statuses(); !slices.Equal(got, want) {
+		t.Fatalf("statuses = %v, want %v", got, want)
+	}
+	if got := history.execs[0].Error; got != errSuperseded.Error() {
+		t.Errorf("cancelled run error = %q, want %q", got, errSuperseded.Error())
+	}
+}
+
+func TestOverlapAllow(t *testing.T) {
+	server := newBlockingServer(t)
+	s, history := newTestScheduler(t)
+	job := types.Job{ID: "allow", Method: "GET", URL: server.URL}
+
+	first := runAsync(s, job)
+	second := runAsync(s, job)
+	server.waitStarted(t)
+	server.waitStarted(t)
+	close(server.release)
+	waitDone(t, first)
+	waitDone(t, second)
+
+	want := []types.ExecutionStatus{types.StatusSuccess, types.StatusSuccess}
+	if got := history.statuses(); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
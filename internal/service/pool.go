@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"tempo/internal/types"
+	"time"
+)
+
+// Default execution limits of a scheduler
+const (
+	DefaultWorkers   = 100
+	DefaultQueueSize = 10000
+)
+
+// ErrQueueFull is returned for an execution that cannot wait for a worker
+// because the queue is full
+var ErrQueueFull = errors.New("execution queue is full")
+
+// errPoolStopped is returned for executions still waiting when the scheduler stops
+var errPoolStopped = errors.New("scheduler stopped before the execution started")
+
+// Task states, so a task is either run by a worker or abandoned by its caller
+const (
+	taskQueued int32 = iota
+	taskRunning
+	taskAbandoned
+)
+
+/*
+* Pool runs executions on a fixed number of workers
+* Executions wait in a bounded queue while every worker is busy
+ */
+type Pool struct {
+	workers int
+	tasks   chan *task
+
+	mu      sync.RWMutex // guards sending tasks against stopping
+	stopped bool
+	stop    chan struct{}
+	start   sync.Once
+	wg      sync.WaitGroup
+
+	busy      atomic.Int64
+	completed atomic.Int64
+	rejected  atomic.Int64
+	waits     atomic.Int64 // tasks taken from the queue
+	waitTotal atomic.Int64 // nanoseconds
+	waitMax   atomic.Int64 // nanoseconds
+}
+
+type task struct {
+	ctx    context.Context
+	run    func()
+	state  atomic.Int32
+	queued time.Time
+	wait   time.Duration
+	err    error
+	done   chan struct{}
+}
+
+/*
+* NewPool creates a pool with the given number of workers and queue size
+* Zero values use DefaultWorkers and DefaultQueueSize
+ */
+func NewPool(workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	return &Pool{
+		workers: workers,
+		tasks:   make(chan *task, queueSize),
+		stop:    make(chan struct{}),
+	}
+}
+
+/*
+* Do runs the function on a worker and waits for it to return
+* It returns how long the execution waited for a worker, ErrQueueFull if it
+* could not be queued, or the context's cause if it ended while waiting
+ */
+func (p *Pool) Do(ctx context.Context, run func()) (time.Duration, error) {
+	p.start.Do(p.startWorkers)
+
+	t := &task{ctx: ctx, run: run, queued: time.Now(), done: make(chan struct{})}
+
+	p.mu.RLock()
+	if p.stopped {
+		p.mu.RUnlock()
+		return 0, errPoolStopped
+	}
+	select {
+	case p.tasks <- t:
+		p.mu.RUnlock()
+	default:
+		p.mu.RUnlock()
+		p.rejected.Add(1)
+		return 0, ErrQueueFull
+	}
+
+	select {
+	case <-t.done:
+		return t.wait, t.err
+	case <-ctx.Done():
+		if t.state.CompareAndSwap(taskQueued, taskAbandoned) {
+			return time.Since(t.queued), context.Cause(ctx)
+		}
+		// A worker already runs it
+		<-t.done
+		return t.wait, t.err
+	}
+}
+
+/*
+* startWorkers starts the pool's workers on first use
+ */
+func (p *Pool) startWorkers() {
+	p.wg.Add(p.workers)
+	for range p.workers {
+		go p.work()
+	}
+}
+
+/*
+* work runs queued tasks until the pool stops
+ */
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		// Prefer stopping over taking more tasks
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case t := <-p.tasks:
+			p.runTask(t)
+		}
+	}
+}
+
+/*
+* runTask runs a task taken from the queue unless its caller gave up on it
+ */
+func (p *Pool) runTask(t *task) {
+	defer close(t.done)
+
+	t.wait = time.Since(t.queued)
+	p.waits.Add(1)
+	p.waitTotal.Add(int64(t.wait))
+	for {
+		waitMax := p.waitMax.Load()
+		if int64(t.wait) <= waitMax || p.waitMax.CompareAndSwap(waitMax, int64(t.wait)) {
+			break
+		}
+	}
+
+	if !t.state.CompareAndSwap(taskQueued, taskRunning) {
+		return
+	}
+
+	p.busy.Add(1)
+	defer p.busy.Add(-1)
+	t.run()
+	p.completed.Add(1)
+}
+
+/*
+* Stop waits for running executions to finish
+* Executions still in the queue fail with errPoolStopped
+ */
+func (p *Pool) Stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.stop)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	for {
+		select {
+		case t := <-p.tasks:
+			if t.state.CompareAndSwap(taskQueued, taskAbandoned) {
+				t.err = errPoolStopped
+			}
+			close(t.done)
+		default:
+			return
+		}
+	}
+}
+
+/*
+* Stats returns the pool's current load and queue wait times
+ */
+func (p *Pool) Stats() types.PoolStats {
+	stats := types.PoolStats{
+		Workers:   p.workers,
+		Busy:      int(p.busy.Load()),
+		Queued:    len(p.tasks),
+		QueueSize: cap(p.tasks),
+		Completed: p.completed.Load(),
+		Rejected:  p.rejected.Load(),
+		WaitMaxMs: time.Duration(p.waitMax.Load()).Milliseconds(),
+	}
+	if waits := p.waits.Load(); waits > 0 {
+		stats.WaitAvgMs = time.Duration(p.waitTotal.Load() / waits).Milliseconds()
+	}
+	return stats
+}
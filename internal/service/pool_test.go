@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"tempo/internal/types"
+	"testing"
+	"time"
+)
+
+// fillPool blocks the only worker of a pool and queues one more execution.
+// It returns a function that lets both finish and a channel receiving their results.
+func fillPool(t *testing.T, p *Pool) (release func(), done chan error) {
+	t.Helper()
+	running := make(chan struct{})
+	unblock := make(chan struct{})
+	done = make(chan error, 2)
+
+	go func() {
+		_, err := p.Do(context.Background(), func() {
+			close(running)
+			<-unblock
+		})
+		done <- err
+	}()
+	<-running
+
+	go func() {
+		_, err := p.Do(context.Background(), func() {})
+		done <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the execution to be queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	return func() { close(unblock) }, done
+}
+
+func TestPoolRejectsWhenQueueFull(t *testing.T) {
+	p := NewPool(1, 1)
+	defer p.Stop()
+	release, done := fillPool(t, p)
+
+	ran := false
+	if _, err := p.Do(context.Background(), func() { ran = true }); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Do() with a full queue = %v, want ErrQueueFull", err)
+	}
+	if ran {
+		t.Error("a rejected execution ran")
+	}
+
+	stats := p.Stats()
+	if stats.Busy != 1 || stats.Queued != 1 || stats.Rejected != 1 {
+		t.Errorf("Stats() = %+v, want 1 busy, 1 queued and 1 rejected", stats)
+	}
+
+	release()
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Errorf("accepted execution failed: %v", err)
+		}
+	}
+	if got := p.Stats().Completed; got != 2 {
+		t.Errorf("Completed = %d, want 2", got)
+	}
+
+	// Room in the queue again
+	if _, err := p.Do(context.Background(), func() {}); err != nil {
+		t.Errorf("Do() after the queue drained = %v", err)
+	}
+}
+
+func TestPoolQueuedExecutionCancelled(t *testing.T) {
+	p := NewPool(1, 2)
+	defer p.Stop()
+	release, done := fillPool(t, p)
+
+	cause := errors.New("superseded")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	result := make(chan error, 1)
+	ran := false
+	go func() {
+		_, err := p.Do(ctx, func() { ran = true })
+		result <- err
+	}()
+	for p.Stats().Queued != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel(cause)
+	if err := <-result; !errors.Is(err, cause) {
+		t.Fatalf("Do() cancelled while queued = %v, want the cancellation cause", err)
+	}
+
+	release()
+	for range 2 {
+		<-done
+	}
+	if ran {
+		t.Error("an execution abandoned in the queue ran")
+	}
+}
+
+func TestPoolStopFailsQueuedExecutions(t *testing.T) {
+	p := NewPool(1, 1)
+	release, done := fillPool(t, p)
+
+	stopped := make(chan struct{})
+	go func() {
+		p.Stop()
+		close(stopped)
+	}()
+	for {
+		p.mu.RLock()
+		stopping := p.stopped
+		p.mu.RUnlock()
+		if stopping {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	release()
+	<-stopped
+
+	errs := []error{<-done, <-done}
+	if !errors.Is(errs[0], errPoolStopped) && !errors.Is(errs[1], errPoolStopped) {
+		t.Errorf("Do() results = %v, want the queued execution to fail with errPoolStopped", errs)
+	}
+	if _, err := p.Do(context.Background(), func() {}); !errors.Is(err, errPoolStopped) {
+		t.Errorf("Do() after Stop = %v, want errPoolStopped", err)
+	}
+}
+
+func TestSchedulerSkipsAttemptWhenQueueFull(t *testing.T) {
+	s, _ := newTestScheduler(t, WithWorkers(1, 1))
+	release, done := fillPool(t, s.pool)
+	defer func() {
+		release()
+		<-done
+		<-done
+	}()
+
+	job := types.Job{ID: "full", Method: "GET", URL: "http://127.0.0.1:1/"}
+	exec, sent, err := s.execute(context.Background(), job, time.Now(), types.TriggerSchedule)
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("execute() = %v, want ErrQueueFull", err)
+	}
+	if sent {
+		t.Error("execute() reports a rejected attempt as sent")
+	}
+	if exec.Status != types.StatusSkipped {
+		t.Errorf("status = %q, want %q", exec.Status, types.StatusSkipped)
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"tempo/internal/logging"
+	"tempo/internal/types"
+)
+
+/*
+* Reload re-reads the job store and applies the changes to the running scheduler
+ */
+func (s *Scheduler) Reload() (types.ReloadSummary, error) {
+	// Serialize reloads so an older snapshot never overwrites a newer one
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if s.store == nil {
+		return types.ReloadSummary{}, fmt.Errorf("scheduler has no job storage to reload from")
+	}
+
+	if err := s.store.Reload(); err != nil {
+		return types.ReloadSummary{}, fmt.Errorf("failed to reload jobs: %v", err)
+	}
+
+	return s.SyncJobs(s.store.GetAllJobs()), nil
+}
+
+/*
+* SyncJobs reconciles the loaded jobs with the given set
+* New jobs are added, missing ones removed and changed ones replaced;
+* unchanged jobs keep their cron entries
+* A replaced job's new entry is scheduled strictly after the reconcile,
+* so a run that already fired for the old entry is never repeated
+ */
+func (s *Scheduler) SyncJobs(jobs []types.Job) types.ReloadSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summary types.ReloadSummary
+
+	wanted := make(map[string]types.Job, len(jobs))
+	for _, job := range jobs {
+		wanted[job.ID] = job
+	}
+
+	for jobID := range s.jobs {
+		if _, ok := wanted[jobID]; !ok {
+			s.removeJobLocked(jobID)
+			if s.metrics != nil {
+				s.metrics.ForgetJob(jobID)
+			}
+			summary.Removed = append(summary.Removed, jobID)
+		}
+	}
+
+	for jobID, job := range wanted {
+		current, loaded := s.jobs[jobID]
+		if loaded && reflect.DeepEqual(current, job) {
+			continue
+		}
+
+		if loaded {
+			s.removeJobLocked(jobID)
+		}
+		if err := s.addJobLocked(job); err != nil {
+			s.log.Error("Failed to add job", logging.KeyJobID, jobID, logging.Err(err))
+			continue
+		}
+
+		if loaded {
+			summary.Updated = append(summary.Updated, jobID)
+		} else {
+			summary.Added = append(summary.Added, jobID)
+		}
+	}
+
+	sort.Strings(summary.Added)
+	sort.Strings(summary.Updated)
+	sort.Strings(summary.Removed)
+
+	return summary
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"tempo/internal/storage"
+	"tempo/internal/templating"
+	"tempo/internal/types"
+	"text/template"
+	"time"
+)
+
+/*
+* ExecutionQuerier reads recorded executions
+* It is satisfied by every storage backend
+ */
+type ExecutionQuerier interface {
+	QueryExecutions(filter storage.HistoryFilter) ([]types.Execution, error)
+	SummarizeExecutions(jobID string) (map[string]storage.JobHistory, error)
+}
+
+/*
+* SecretResolver looks up the values of secrets referenced by job templates
+ */
+type SecretResolver interface {
+	Secret(name string) (string, error)
+}
+
+/*
+* CountRuns returns how many runs of the job have been recorded
+* Retries belong to their run, so only first attempts are counted
+ */
+func CountRuns(history ExecutionQuerier, jobID string) (int64, error) {
+	jobs, err := history.SummarizeExecutions(jobID)
+	if err != nil {
+		return 0, err
+	}
+	return jobs[jobID].Runs, nil
+}
+
+/*
+* RenderJob expands the templates in the job's request for a single run
+* Secrets are only resolved when a resolver is given
+* It also returns the secret and environment values the templates used,
+* even when rendering fails, so they can be redacted from the run's output
+ */
+func RenderJob(job types.Job, run int64, runID string, scheduledAt time.Time, secrets SecretResolver) (types.Job, []string, error) {
+	var used []string
+	funcs := template.FuncMap{
+		"env": func(name string) (string, error) {
+			value, err := templating.Env(name)
+			if err == nil {
+				used = append(used, value)
+			}
+			return value, err
+		},
+	}
+	if secrets != nil {
+		funcs["secret"] = func(name string) (string, error) {
+			value, err := secrets.Secret(name)
+			if err == nil {
+				used = append(used, value)
+			}
+			return value, err
+		}
+	}
+
+	request, err := templating.Render(job, templating.Data{
+		JobID:     job.ID,
+		RunID:     runID,
+		Run:       run,
+		Scheduled: scheduledAt,
+		Now:       time.Now(),
+	}, funcs)
+	return request, used, err
+}
+
+/*
+* nextRun returns the run number for a new run of the job
+* Counters are only loaded from history for jobs that use templates
+ */
+func (s *Scheduler) nextRun(job types.Job) int64 {
+	s.mu.Lock()
+	if runs, ok := s.runs[job.ID]; ok {
+		s.runs[job.ID] = runs + 1
+		s.mu.Unlock()
+		return runs + 1
+	}
+	s.mu.Unlock()
+
+	if !templating.HasTemplates(job) {
+		return 0
+	}
+
+	var runs int64
+	if querier, ok := s.history.(ExecutionQuerier); ok {
+		counted, err := CountRuns(querier, job.ID)
+		if err == nil {
+			runs = counted
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another run may have loaded the counter meanwhile
+	if current, ok := s.runs[job.ID]; ok {
+		runs = current
+	}
+	s.runs[job.ID] = runs + 1
+	return runs + 1
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"tempo/internal/types"
+	"time"
+)
+
+/*
+* maxAttempts returns how many times a job may be attempted per run
+ */
+func maxAttempts(policy *types.RetryPolicy) int {
+	if policy == nil || policy.MaxAttempts < 1 {
+		return 1
+	}
+	return policy.MaxAttempts
+}
+
+/*
+* shouldRetry decides whether a failed attempt is retryable under the policy
+* Responses are matched by status code, missing responses by error kind
+ */
+func shouldRetry(policy *types.RetryPolicy, exec types.Execution, err error) bool {
+	if policy == nil || err == nil {
+		return false
+	}
+
+	if exec.StatusCode != 0 {
+		return policy.MatchesStatus(exec.StatusCode)
+	}
+
+	var webhookErr *WebhookError
+	if !errors.As(err, &webhookErr) || webhookErr.Err == nil {
+		// the request could not even be built, retrying won't help
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return policy.Matches(types.RetryOnTimeout)
+	}
+	return policy.Matches(types.RetryOnNetwork)
+}
+
+/*
+* retryDelay returns how long to wait after the given failed attempt
+* The delay grows exponentially, is randomized by Jitter and capped by MaxDelay
+ */
+func retryDelay(policy *types.RetryPolicy, attempt int) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(policy.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if policy.Jitter > 0 {
+		// spread the delay uniformly over [delay*(1-jitter), delay*(1+jitter)]
+		delay += delay * policy.Jitter * (2*rand.Float64() - 1)
+	}
+
+	// capping last keeps jitter from pushing a delay beyond the limit
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+	// without a cap the delay can outgrow what a Duration holds
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(delay)
+}
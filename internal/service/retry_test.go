@@ -0,0 +1,84 @@
+package service
+
+import (
+	"math"
+	"tempo/internal/types"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  types.RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"first retry", types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 2}, 1, time.Second},
+		{"grows exponentially", types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 2}, 4, 8 * time.Second},
+		{"constant without multiplier", types.RetryPolicy{InitialDelay: types.Duration(time.Second)}, 5, time.Second},
+		{"capped", types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 2, MaxDelay: types.Duration(5 * time.Second)}, 10, 5 * time.Second},
+		{"zero max delay is unbounded", types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 10}, 4, 1000 * time.Second},
+		{"unbounded delay saturates", types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 10}, 40, time.Duration(math.MaxInt64)},
+		{"unbounded jittered delay saturates", types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 2, Jitter: 0.1}, 100, time.Duration(math.MaxInt64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(&tt.policy, tt.attempt); got != tt.want {
+				t.Errorf("retryDelay(attempt %d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelayJitterBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   types.RetryPolicy
+		attempt  int
+		min, max time.Duration
+	}{
+		{
+			name:    "uncapped",
+			policy:  types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 2, Jitter: 0.5},
+			attempt: 3,
+			min:     2 * time.Second,
+			max:     6 * time.Second,
+		},
+		{
+			name:    "jitter never exceeds the cap",
+			policy:  types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 2, MaxDelay: types.Duration(3 * time.Second), Jitter: 1},
+			attempt: 3,
+			min:     0,
+			max:     3 * time.Second,
+		},
+		{
+			name:    "far beyond the cap",
+			policy:  types.RetryPolicy{InitialDelay: types.Duration(time.Second), Multiplier: 2, MaxDelay: types.Duration(10 * time.Second), Jitter: 0.2},
+			attempt: 20,
+			min:     10 * time.Second,
+			max:     10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				got := retryDelay(&tt.policy, tt.attempt)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("retryDelay(attempt %d) = %v, want within [%v, %v]", tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxAttempts(t *testing.T) {
+	if got := maxAttempts(nil); got != 1 {
+		t.Errorf("maxAttempts(nil) = %d, want 1", got)
+	}
+	if got := maxAttempts(&types.RetryPolicy{MaxAttempts: 4}); got != 4 {
+		t.Errorf("maxAttempts(4) = %d, want 4", got)
+	}
+}
@@ -2,24 +2,101 @@ package service
 
 // imports
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 
+	"tempo/internal/assertion"
+	"tempo/internal/events"
+	"tempo/internal/logging"
+	"tempo/internal/redact"
+	"tempo/internal/schedule"
+	"tempo/internal/storage"
+	"tempo/internal/templating"
 	"tempo/internal/types"
 	"time"
 
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // structs
 
 type Scheduler struct {
-	Cron   *cron.Cron
-	ctx    context.Context
-	cancel context.CancelFunc
+	Cron    *cron.Cron
+	ctx     context.Context
+	cancel  context.CancelFunc
+	history Recorder
+	events  *events.Bus
+	store   JobStore
+	secrets SecretResolver
+	alerts  Alerter
+	metrics ExecutionObserver
+	log     *slog.Logger
+	redact  *redact.Redactor  // nil applies the default rules
+	http    *types.HTTPConfig // defaults of the jobs' HTTP client settings
+
+	pool       *Pool       // runs every execution, bounding concurrency
+	transports *Transports // shares connections between executions
+
+	reloadMu sync.Mutex
+	mu       sync.Mutex
+	entries  map[string]cron.EntryID
+	jobs     map[string]types.Job
+	runs     map[string]int64     // run numbers of jobs that use templates
+	fired    map[string]time.Time // run times of one-shot jobs fired by this scheduler
+	overlaps map[string]*overlap  // scheduled runs in progress of jobs with an overlap policy
+}
+
+// JobStore is the persistent job source a scheduler reloads from.
+// One-shot jobs are marked as completed in it after they fire.
+type JobStore interface {
+	Reload() error
+	GetAllJobs() []types.Job
+	UpdateJob(id string, change func(job types.Job, exists bool) (types.Job, error)) error
+}
+
+/*
+* Alerter is told the outcome of every run, to alert on failing jobs
+ */
+type Alerter interface {
+	Observe(job types.Job, exec types.Execution)
+}
+
+/*
+* ExecutionObserver is told about every execution attempt, e.g. to count it in metrics
+* sent tells whether the attempt got to send its request; ForgetJob is called
+* for jobs removed from the scheduler
+ */
+type ExecutionObserver interface {
+	ObserveExecution(exec types.Execution, sent bool)
+	ForgetJob(jobID string)
+}
+
+// ErrJobNotLoaded is returned for operations on jobs the scheduler doesn't know about
+var ErrJobNotLoaded = errors.New("job is not loaded in the scheduler")
+
+// Option configures optional scheduler dependencies
+type Option func(*Scheduler)
+
+// webhookResponse holds the parts of a webhook response worth recording or asserting on
+type webhookResponse struct {
+	StatusCode int
+	Size       int64
+	Header     http.Header
+	Body       []byte // only kept when the job's assertions need it
+	Truncated  bool   // the body was longer than assertion.MaxBodySize
 }
 
 /*
@@ -29,6 +106,7 @@ type Scheduler struct {
 type WebhookError struct {
 	StatusCode int
 	Message    string
+	Err        error // underlying transport error, if the request could not be sent
 }
 
 /*
@@ -36,26 +114,152 @@ type WebhookError struct {
 * It returns the status code and message of the webhook error
  */
 func (e *WebhookError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("webhook returned status code: %d, message: %s: %v", e.StatusCode, e.Message, e.Err)
+	}
 	return fmt.Sprintf("webhook returned status code: %d, message: %s", e.StatusCode, e.Message)
 }
 
+/*
+* Unwrap returns the underlying transport error
+ */
+func (e *WebhookError) Unwrap() error {
+	return e.Err
+}
+
 /*
 * NewScheduler creates a new scheduler instance
 * It creates a context with a cancel function and a cron instance
 * It returns a new scheduler instance
  */
-func NewScheduler() *Scheduler {
+func NewScheduler(opts ...Option) *Scheduler {
 	// create a context with a cancel function
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// create a cron instance
-	cron := cron.New(cron.WithSeconds())
+	c := cron.New(cron.WithSeconds())
+
+	// create a new scheduler instance and apply options
+	s := &Scheduler{
+		Cron:     c,
+		ctx:      ctx,
+		cancel:   cancel,
+		entries:  make(map[string]cron.EntryID),
+		jobs:     make(map[string]types.Job),
+		runs:     make(map[string]int64),
+		fired:    make(map[string]time.Time),
+		overlaps: make(map[string]*overlap),
+
+		log:        slog.Default(),
+		pool:       NewPool(0, 0),
+		transports: NewTransports(0),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+/*
+* WithEvents publishes execution events on the given bus
+ */
+func WithEvents(bus *events.Bus) Option {
+	return func(s *Scheduler) {
+		s.events = bus
+	}
+}
+
+/*
+* WithStorage lets the scheduler reload its jobs from the given store
+ */
+func WithStorage(store JobStore) Option {
+	return func(s *Scheduler) {
+		s.store = store
+	}
+}
+
+/*
+* WithSecrets resolves {{ secret "name" }} in job templates with the given resolver
+ */
+func WithSecrets(secrets SecretResolver) Option {
+	return func(s *Scheduler) {
+		s.secrets = secrets
+	}
+}
+
+/*
+* WithAlerts reports the outcome of every run to the given alerter
+ */
+func WithAlerts(alerts Alerter) Option {
+	return func(s *Scheduler) {
+		s.alerts = alerts
+	}
+}
+
+/*
+* WithMetrics reports every execution attempt to the given observer
+ */
+func WithMetrics(metrics ExecutionObserver) Option {
+	return func(s *Scheduler) {
+		s.metrics = metrics
+	}
+}
+
+/*
+* WithLogger writes the scheduler's logs to the given logger instead of slog's default
+ */
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scheduler) {
+		s.log = logger
+	}
+}
+
+/*
+* WithRedactor masks sensitive data in the scheduler's logs, history and events
+ */
+func WithRedactor(redactor *redact.Redactor) Option {
+	return func(s *Scheduler) {
+		s.redact = redactor
+	}
+}
+
+/*
+* WithHTTPDefaults applies the given HTTP client settings to jobs that don't set them
+ */
+func WithHTTPDefaults(defaults *types.HTTPConfig) Option {
+	return func(s *Scheduler) {
+		s.http = defaults
+	}
+}
+
+/*
+* WithWorkers limits how many executions run at the same time and how many
+* may wait for a worker, zero for the defaults
+* Executions that find the queue full are skipped
+ */
+func WithWorkers(workers, queueSize int) Option {
+	return func(s *Scheduler) {
+		s.pool = NewPool(workers, queueSize)
+	}
+}
+
+/*
+* WithMaxConnsPerHost limits the connections to a single host, zero means unlimited
+* Executions beyond the limit wait for a connection
+ */
+func WithMaxConnsPerHost(limit int) Option {
+	return func(s *Scheduler) {
+		s.transports = NewTransports(limit)
+	}
+}
 
-	// return a new scheduler instance
-	return &Scheduler{
-		Cron:   cron,
-		ctx:    ctx,
-		cancel: cancel,
+/*
+* WithHistory records every scheduled execution with the given recorder
+ */
+func WithHistory(history Recorder) Option {
+	return func(s *Scheduler) {
+		s.history = history
 	}
 }
 
@@ -64,20 +268,29 @@ func NewScheduler() *Scheduler {
 * It calls the webhook and returns an error if the webhook returns a status code >= 400
  */
 func CallWebhook(job types.Job) error {
-	log.Printf("Calling webhook: %v, method: %v, body: %v, headers: %v", job.URL, job.Method, job.Body, job.Headers)
+	_, err := callWebhook(context.Background(), defaultTransports, job)
+	return err
+}
 
-	// create a new http client
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+/*
+* callWebhook sends the job's request and drains the response
+* It returns the response status code and body size alongside any error
+* Cancelling the context aborts the request
+ */
+func callWebhook(ctx context.Context, transports *Transports, job types.Job) (*webhookResponse, error) {
+	// get the shared client for the job's settings
+	client, err := transports.Client(job.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %v", err)
 	}
 
 	// create request with body, method and headers
-	req, err := http.NewRequest(job.Method, job.URL, strings.NewReader(job.Body))
+	req, err := http.NewRequestWithContext(ctx, job.Method, job.URL, strings.NewReader(job.Body))
 	if err != nil {
-		log.Printf("Error creating request: %v", err)
-		return &WebhookError{
+		return nil, &WebhookError{
 			StatusCode: 500,
 			Message:    "Error creating request",
+			Err:        err,
 		}
 	}
 
@@ -86,30 +299,57 @@ func CallWebhook(job types.Job) error {
 		req.Header.Add(key, value)
 	}
 
+	// trace the request and pass the trace context on to the webhook
+	ctx, span := tracer.Start(ctx, req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(requestAttributes(req)...))
+	defer span.End()
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
 	// send request
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Error sending request: %v", err)
-		return &WebhookError{
+		// The error may name the URL, the attempt span records it redacted
+		span.SetStatus(codes.Error, "request failed")
+		return nil, &WebhookError{
 			StatusCode: 500,
 			Message:    "Error sending request",
+			Err:        err,
 		}
 	}
 
 	defer resp.Body.Close()
 
-	// check if status code is >= 400
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 	if resp.StatusCode >= 400 {
-		return &WebhookError{
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	result := &webhookResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+
+	// keep the start of the body for assertions, drain the rest so its size can be recorded
+	if assertion.NeedsBody(job.Assert) {
+		var body bytes.Buffer
+		n, _ := io.Copy(&body, io.LimitReader(resp.Body, assertion.MaxBodySize))
+		rest, _ := io.Copy(io.Discard, resp.Body)
+		result.Body = body.Bytes()
+		result.Truncated = rest > 0
+		result.Size = n + rest
+	} else {
+		result.Size, _ = io.Copy(io.Discard, resp.Body)
+	}
+
+	// check if status code is >= 400, unless the job asserts on the status itself
+	if resp.StatusCode >= 400 && !assertion.ChecksStatus(job.Assert) {
+		return result, &WebhookError{
 			StatusCode: resp.StatusCode,
 			Message:    resp.Status,
 		}
 	}
 
-	// log response
-	log.Printf("Response: %v", resp)
-
-	return nil
+	return result, nil
 }
 
 /*
@@ -118,20 +358,460 @@ func CallWebhook(job types.Job) error {
 * It calls the webhook and logs the result
  */
 func (s *Scheduler) AddJob(job types.Job) {
-	_, err := s.Cron.AddFunc(job.CronExpr, func() {
-		err := CallWebhook(job)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.addJobLocked(job); err != nil {
+		s.log.Error("Failed to add job", logging.KeyJobID, job.ID, logging.Err(err))
+	}
+}
+
+/*
+* addJobLocked registers the job with cron, in the job's time zone
+* The caller must hold s.mu
+ */
+func (s *Scheduler) addJobLocked(job types.Job) error {
+	sched, err := schedule.Parse(job)
+	if err != nil {
+		return err
+	}
+
+	if job.CompletedAt != nil {
+		// Keep completed one-shot jobs available to Trigger only
+		s.jobs[job.ID] = job
+		return nil
+	}
+	if job.ScheduleKind() == types.ScheduleAt {
+		s.addOneShotLocked(job)
+		return nil
+	}
 
-		if err != nil {
-			log.Printf("Error calling webhook: %v", err)
-		} else {
-			log.Printf("Job %s executed successfully", job.URL)
+	id := s.Cron.Schedule(sched, cron.FuncJob(func() {
+		if job.IsPaused(time.Now()) {
+			return
 		}
+		s.runScheduled(job, s.scheduledTime(job.ID))
+	}))
 
+	s.entries[job.ID] = id
+	s.jobs[job.ID] = job
+	return nil
+}
+
+/*
+* addOneShotLocked schedules a one-shot job for its time, or for the end of
+* a pause that lasts beyond it
+* A job whose time passed while the scheduler was stopped or the job was
+* paused is fired right away
+* The caller must hold s.mu
+ */
+func (s *Scheduler) addOneShotLocked(job types.Job) {
+	s.jobs[job.ID] = job
+
+	now := time.Now()
+	due := *job.At
+	if job.IsPaused(now) {
+		if job.PausedUntil == nil {
+			// Resuming the job reloads it
+			return
+		}
+		if job.PausedUntil.After(due) {
+			due = *job.PausedUntil
+		}
+	}
+
+	if !due.After(now) {
+		go s.fireOnce(job)
+		return
+	}
+	s.entries[job.ID] = s.Cron.Schedule(schedule.Once(due), cron.FuncJob(func() {
+		s.fireOnce(job)
+	}))
+}
+
+/*
+* fireOnce runs a one-shot job and marks it as completed
+* A run recorded before a restart is not repeated, the job is only completed
+ */
+func (s *Scheduler) fireOnce(job types.Job) {
+	s.mu.Lock()
+	if fired, ok := s.fired[job.ID]; ok && fired.Equal(*job.At) {
+		s.mu.Unlock()
+		return
+	}
+	s.fired[job.ID] = *job.At
+	s.mu.Unlock()
+
+	if s.firedBefore(job) {
+		s.log.Info("One-shot job already ran", logging.KeyJobID, job.ID, "scheduled_at", *job.At)
+	} else {
+		if time.Since(*job.At) > time.Second {
+			s.log.Info("Running overdue one-shot job", logging.KeyJobID, job.ID, "scheduled_at", *job.At)
+		}
+		s.runJob(context.Background(), job, *job.At, types.TriggerSchedule)
+	}
+
+	s.complete(job)
+}
+
+/*
+* firedBefore reports whether the history holds a scheduled run of the one-shot job
+ */
+func (s *Scheduler) firedBefore(job types.Job) bool {
+	querier, ok := s.history.(ExecutionQuerier)
+	if !ok {
+		return false
+	}
+
+	execs, err := querier.QueryExecutions(storage.HistoryFilter{JobID: job.ID, Since: *job.At})
+	if err != nil {
+		s.log.Error("Failed to read job history", logging.KeyJobID, job.ID, logging.Err(err))
+		return false
+	}
+	for _, exec := range execs {
+		if exec.Trigger == types.TriggerSchedule && exec.ScheduledAt.Equal(*job.At) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+* complete records in the job store that a one-shot job fired
+* Jobs changed in the meantime, e.g. to a new time, are left alone
+ */
+func (s *Scheduler) complete(job types.Job) {
+	if s.store == nil {
+		return
+	}
+	// The job is marked under the storage lock, so a change saved by another
+	// process since the job was loaded is kept
+	completed := false
+	err := s.store.UpdateJob(job.ID, func(current types.Job, exists bool) (types.Job, error) {
+		if !exists || current.At == nil || !current.At.Equal(*job.At) || current.CompletedAt != nil {
+			return types.Job{}, storage.ErrJobUnchanged
+		}
+		now := time.Now()
+		current.CompletedAt = &now
+		completed = true
+		return current, nil
 	})
+	if err != nil {
+		s.log.Error("Failed to complete one-shot job", logging.KeyJobID, job.ID, logging.Err(err))
+		return
+	}
+	if !completed {
+		return
+	}
+	s.log.Info("One-shot job completed", logging.KeyJobID, job.ID)
 
+	if _, err := s.Reload(); err != nil {
+		s.log.Error("Failed to reload jobs", logging.Err(err))
+	}
+}
+
+/*
+* removeJobLocked unregisters the job from cron
+* The caller must hold s.mu
+ */
+func (s *Scheduler) removeJobLocked(jobID string) {
+	if id, ok := s.entries[jobID]; ok {
+		s.Cron.Remove(id)
+	}
+	delete(s.entries, jobID)
+	delete(s.jobs, jobID)
+}
+
+/*
+* Trigger runs a loaded job immediately, outside of its schedule
+* It returns the last attempt made, honoring the job's retry policy
+ */
+func (s *Scheduler) Trigger(jobID string) (types.Execution, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[jobID]
+	s.mu.Unlock()
+
+	if !ok {
+		return types.Execution{}, fmt.Errorf("%w: %s", ErrJobNotLoaded, jobID)
+	}
+
+	return s.runJob(context.Background(), job, time.Now(), types.TriggerManual)
+}
+
+/*
+* runJob executes a run of the job in a span that the spans of its attempts
+* belong to, and reports its outcome for alerting
+ */
+func (s *Scheduler) runJob(ctx context.Context, job types.Job, scheduledAt time.Time, trigger string) (types.Execution, error) {
+	ctx, span := tracer.Start(ctx, "run "+job.ID, trace.WithAttributes(
+		attrJobID.String(job.ID),
+		attrTrigger.String(trigger),
+		attrScheduledAt.String(scheduledAt.Format(time.RFC3339)),
+	))
+	exec, err := s.runAttempts(ctx, job, scheduledAt, trigger)
+	endExecutionSpan(span, exec)
+
+	if s.alerts != nil {
+		s.alerts.Observe(job, exec)
+	}
+	return exec, err
+}
+
+/*
+* runAttempts executes a run of the job
+* It retries failed attempts according to the job's retry policy,
+* publishing events and recording every attempt separately
+* Cancelling the context aborts the run, a run cancelled by a newer run
+* is recorded as cancelled
+ */
+func (s *Scheduler) runAttempts(ctx context.Context, job types.Job, scheduledAt time.Time, trigger string) (types.Execution, error) {
+	attempts := maxAttempts(job.Retry)
+	runID := templating.NewUUID()
+	run := trace.SpanFromContext(ctx)
+	run.SetAttributes(attrRunID.String(runID))
+
+	// Render once per run so retries send the same request, and mask the
+	// secrets and environment values it used wherever the run is logged or
+	// recorded
+	request, used, err := RenderJob(job, s.nextRun(job), runID, scheduledAt, s.secrets)
+	redactor := s.redact.WithValues(used...)
 	if err != nil {
-		log.Printf("Error adding job: %v", err)
+		err = redactor.Error(err)
+		exec := failedExecution(job, scheduledAt, trigger, err)
+		exec.RunID = runID
+		s.record(exec, false)
+		s.publish(executionEvent(exec, 1))
+		s.log.Error("Failed to render job", executionAttrs(exec)...)
+		return exec, err
 	}
+	request.HTTP = job.HTTP.WithDefaults(s.http)
+
+	for attempt := 1; ; attempt++ {
+		s.publish(types.Event{Type: types.EventStart, Time: time.Now(), JobID: job.ID, Attempt: attempt})
+		s.log.Debug("Calling webhook", logging.KeyJobID, job.ID, "run_id", runID, logging.KeyAttempt, attempt, "trigger", trigger)
+
+		attemptCtx, span := tracer.Start(ctx, "attempt", trace.WithAttributes(
+			attrAttempt.Int(attempt),
+			semconv.URLFull(redactor.URL(request.URL)),
+		))
+		exec, sent, err := s.execute(attemptCtx, request, scheduledAt, trigger)
+		exec.RunID = runID
+		exec.Attempt = attempt
+		superseded := err != nil && errors.Is(context.Cause(ctx), errSuperseded)
+		if superseded {
+			err = errSuperseded
+			exec.Status = types.StatusCancelled
+			exec.Error = err.Error()
+		}
+		exec = redactor.Execution(exec)
+		span.SetAttributes(attrQueueMs.Int64(exec.QueueMs))
+		endExecutionSpan(span, exec)
+		s.record(exec, sent)
+		s.publish(executionEvent(exec, attempt))
+
+		if err == nil {
+			s.log.Info("Execution succeeded", executionAttrs(exec)...)
+			return exec, nil
+		}
+
+		if superseded {
+			s.log.Warn("Execution cancelled", executionAttrs(exec)...)
+			return exec, err
+		}
+		if exec.Status == types.StatusSkipped {
+			s.log.Warn("Execution skipped", executionAttrs(exec)...)
+			return exec, err
+		}
+
+		retry := attempt < attempts && shouldRetry(job.Retry, exec, err)
+		err = redactor.Error(err)
+		if !retry {
+			s.log.Error("Execution failed", executionAttrs(exec)...)
+			return exec, err
+		}
+
+		delay := retryDelay(job.Retry, attempt)
+		run.AddEvent("retry", trace.WithAttributes(attrAttempt.Int(attempt), attrDelayMs.Int64(delay.Milliseconds())))
+		s.log.Warn("Execution failed, retrying", append(executionAttrs(exec), "max_attempts", attempts, "retry_in_ms", delay.Milliseconds())...)
+		s.publish(types.Event{
+			Type:        types.EventRetry,
+			Time:        time.Now(),
+			JobID:       job.ID,
+			ExecutionID: exec.ID,
+			Attempt:     attempt,
+			StatusCode:  exec.StatusCode,
+			DelayMs:     delay.Milliseconds(),
+			Error:       exec.Error,
+		})
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			s.log.Warn("Run cancelled before retrying", logging.KeyJobID, job.ID, "run_id", runID, logging.Err(context.Cause(ctx)))
+			return exec, err
+		case <-s.ctx.Done():
+			return exec, err
+		}
+	}
+}
+
+/*
+* execute makes one attempt of a run on a worker of the pool
+* It reports whether a worker took the attempt; an attempt the pool can't
+* take is recorded as skipped
+ */
+func (s *Scheduler) execute(ctx context.Context, job types.Job, scheduledAt time.Time, trigger string) (types.Execution, bool, error) {
+	var exec types.Execution
+	var err error
+	wait, poolErr := s.pool.Do(ctx, func() {
+		exec, err = execute(ctx, s.transports, job, scheduledAt, trigger)
+	})
+	if poolErr != nil {
+		exec, err = failedExecution(job, scheduledAt, trigger, poolErr), poolErr
+		if errors.Is(poolErr, ErrQueueFull) || errors.Is(poolErr, errPoolStopped) {
+			exec.Status = types.StatusSkipped
+		}
+	}
+	exec.QueueMs = wait.Milliseconds()
+	return exec, poolErr == nil, err
+}
+
+/*
+* PoolStats returns the load of the scheduler's execution workers
+ */
+func (s *Scheduler) PoolStats() types.PoolStats {
+	return s.pool.Stats()
+}
+
+/*
+* scheduledTime returns the fire time cron scheduled the job's current run for
+* It falls back to the current time if the entry is unknown
+ */
+func (s *Scheduler) scheduledTime(jobID string) time.Time {
+	s.mu.Lock()
+	id, ok := s.entries[jobID]
+	s.mu.Unlock()
+
+	if ok {
+		if entry := s.Cron.Entry(id); !entry.Prev.IsZero() {
+			return entry.Prev
+		}
+	}
+	return time.Now()
+}
+
+/*
+* record stores an execution in the scheduler's history, if one is configured,
+* and reports it to the metrics
+* sent tells whether the execution got to send its request
+ */
+func (s *Scheduler) record(exec types.Execution, sent bool) {
+	if s.metrics != nil {
+		s.metrics.ObserveExecution(exec, sent)
+	}
+	if s.history == nil {
+		return
+	}
+	if err := s.history.RecordExecution(exec); err != nil {
+		s.log.Error("Failed to record execution", logging.KeyJobID, exec.JobID, logging.KeyExecutionID, exec.ID, logging.Err(err))
+	}
+}
+
+/*
+* publish sends an event to live followers, if an event bus is configured
+ */
+func (s *Scheduler) publish(event types.Event) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(event)
+}
+
+/*
+* executionEvent describes the outcome of an execution as a live event
+ */
+func executionEvent(exec types.Execution, attempt int) types.Event {
+	eventType := types.EventFailure
+	switch exec.Status {
+	case types.StatusSuccess:
+		eventType = types.EventSuccess
+	case types.StatusSkipped:
+		eventType = types.EventSkipped
+	case types.StatusCancelled:
+		eventType = types.EventCancelled
+	}
+
+	return types.Event{
+		Type:        eventType,
+		Time:        exec.FinishedAt,
+		JobID:       exec.JobID,
+		ExecutionID: exec.ID,
+		Attempt:     attempt,
+		StatusCode:  exec.StatusCode,
+		LatencyMs:   exec.LatencyMs,
+		Error:       exec.Error,
+	}
+}
+
+/*
+* Jobs returns every loaded job with its next and previous fire times
+* Jobs without a next run, such as completed one-shot jobs and jobs paused
+* without an end, are included and come last
+ */
+func (s *Scheduler) Jobs() []types.ScheduledJob {
+	s.mu.Lock()
+	jobIDs := make(map[cron.EntryID]string, len(s.entries))
+	for jobID, id := range s.entries {
+		jobIDs[id] = jobID
+	}
+	jobs := make(map[string]types.Job, len(s.jobs))
+	for jobID, job := range s.jobs {
+		jobs[jobID] = job
+	}
+	s.mu.Unlock()
+
+	entries := make(map[string]cron.Entry, len(jobIDs))
+	for _, entry := range s.Cron.Entries() {
+		if jobID, ok := jobIDs[entry.ID]; ok {
+			entries[jobID] = entry
+		}
+	}
+
+	now := time.Now()
+	scheduled := make([]types.ScheduledJob, 0, len(jobs))
+	for jobID, job := range jobs {
+		paused := job.IsPaused(now)
+		var next, prev time.Time
+		if entry, ok := entries[jobID]; ok {
+			next, prev = entry.Next, entry.Prev
+			if paused {
+				// The next run is the first one after an automatic resume, if any
+				next = time.Time{}
+				if job.PausedUntil != nil {
+					next = entry.Schedule.Next(job.PausedUntil.Add(-time.Nanosecond))
+				}
+			}
+		}
+
+		scheduled = append(scheduled, types.ScheduledJob{
+			Job:     job,
+			NextRun: next,
+			PrevRun: prev,
+			Paused:  paused,
+		})
+	}
+
+	sort.Slice(scheduled, func(i, j int) bool {
+		a, b := scheduled[i].NextRun, scheduled[j].NextRun
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return scheduled[i].Job.ID < scheduled[j].Job.ID
+	})
+	return scheduled
 }
 
 /*
@@ -140,16 +820,20 @@ func (s *Scheduler) AddJob(job types.Job) {
  */
 func (s *Scheduler) Start() {
 	s.Cron.Start()
-	log.Println("[INFO] Scheduler started")
+	s.log.Info("Scheduler started")
 }
 
 /*
 * Stop stops the scheduler
-* It stops the scheduler and logs a message
+* It stops scheduling new runs, abandons pending retries and queued
+* executions, waits for running executions to finish and logs a message
  */
 func (s *Scheduler) Stop() {
-	s.Cron.Stop()
+	running := s.Cron.Stop()
 	s.cancel()
+	s.pool.Stop()
+	<-running.Done()
+	s.transports.CloseIdle()
 
-	log.Println("[INFO] Scheduler stopped")
+	s.log.Info("Scheduler stopped")
 }
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"testing"
+	"time"
+)
+
+func TestCompleteKeepsConcurrentUpdate(t *testing.T) {
+	dir := t.TempDir()
+	cli, err := storage.NewJSONStorage(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon, err := storage.NewJSONStorage(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Now().Add(-time.Minute)
+	job := types.Job{ID: "once", Method: "GET", URL: "https://example.com/old", At: &at}
+	if err := cli.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestScheduler(t, WithStorage(daemon))
+	if err := daemon.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process changes the URL after the scheduler loaded the job
+	updated := job
+	updated.URL = "https://example.com/new"
+	if err := cli.AddJob(updated); err != nil {
+		t.Fatal(err)
+	}
+	s.complete(job)
+
+	if err := cli.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := cli.GetJob("once")
+	if stored.CompletedAt == nil {
+		t.Error("the one-shot job was not marked as completed")
+	}
+	if stored.URL != updated.URL {
+		t.Errorf("URL = %q, want the concurrent update %q kept", stored.URL, updated.URL)
+	}
+
+	// A job moved to a new time is left alone
+	later := time.Now().Add(time.Hour)
+	rescheduled := updated
+	rescheduled.At, rescheduled.CompletedAt = &later, nil
+	if err := cli.AddJob(rescheduled); err != nil {
+		t.Fatal(err)
+	}
+	s.complete(job)
+	if err := cli.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := cli.GetJob("once"); stored.CompletedAt != nil {
+		t.Error("a rescheduled one-shot job was marked as completed")
+	}
+}
+
+func TestJobsIncludesJobsWithoutEntries(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	now := time.Now()
+	past, future, resume := now.Add(-time.Hour), now.Add(2*time.Hour), now.Add(time.Hour)
+
+	s.AddJob(types.Job{ID: "hourly", Method: "GET", URL: "https://example.com/", CronExpr: "0 0 * * * *"})
+	s.AddJob(types.Job{ID: "paused-until", Method: "GET", URL: "https://example.com/", CronExpr: "0 0 * * * *", Paused: true, PausedUntil: &resume})
+	s.AddJob(types.Job{ID: "completed", Method: "GET", URL: "https://example.com/", At: &past, CompletedAt: &past})
+	s.AddJob(types.Job{ID: "paused-once", Method: "GET", URL: "https://example.com/", At: &future, Paused: true})
+	s.Start()
+
+	jobs := s.Jobs()
+	var ids []string
+	for _, job := range jobs {
+		ids = append(ids, job.Job.ID)
+	}
+	// Jobs without a next run come last
+	if want := []string{"hourly", "paused-until", "completed", "paused-once"}; !slices.Equal(ids, want) {
+		t.Fatalf("Jobs() = %v, want %v", ids, want)
+	}
+
+	if next := jobs[1].NextRun; next.Before(resume) || !jobs[1].Paused {
+		t.Errorf("paused job with a resume time: next run %v, paused %v; want the first run after %v", next, jobs[1].Paused, resume)
+	}
+	for _, job := range jobs[2:] {
+		if !job.NextRun.IsZero() {
+			t.Errorf("%s has next run %v, want none", job.Job.ID, job.NextRun)
+		}
+	}
+	if !jobs[3].Paused {
+		t.Error("indefinitely paused one-shot job is not reported as paused")
+	}
+}
+
+// observer records what the scheduler reports to its metrics
+type observer struct {
+	mu     sync.Mutex
+	sent   map[string][]bool
+	forgot []string
+}
+
+func (o *observer) ObserveExecution(exec types.Execution, sent bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.sent == nil {
+		o.sent = make(map[string][]bool)
+	}
+	o.sent[exec.JobID] = append(o.sent[exec.JobID], sent)
+}
+
+func (o *observer) ForgetJob(jobID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.forgot = append(o.forgot, jobID)
+}
+
+func TestSchedulerReportsToMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	metrics := &observer{}
+	s, _ := newTestScheduler(t, WithMetrics(metrics))
+	sent := types.Job{ID: "sent", Method: "GET", URL: server.URL, CronExpr: "0 0 * * * *"}
+	unrendered := types.Job{ID: "unrendered", Method: "GET", URL: server.URL + `/{{ secret "missing" }}`, CronExpr: "0 0 * * * *"}
+	s.SyncJobs([]types.Job{sent, unrendered})
+
+	s.runScheduled(sent, time.Now())
+	s.runScheduled(unrendered, time.Now())
+	if got := metrics.sent["sent"]; !slices.Equal(got, []bool{true}) {
+		t.Errorf("executions of a sent request reported as sent = %v, want [true]", got)
+	}
+	if got := metrics.sent["unrendered"]; !slices.Equal(got, []bool{false}) {
+		t.Errorf("executions of a job that failed to render reported as sent = %v, want [false]", got)
+	}
+
+	s.SyncJobs([]types.Job{sent})
+	if !slices.Equal(metrics.forgot, []string{"unrendered"}) {
+		t.Errorf("forgotten jobs = %v, want [unrendered]", metrics.forgot)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"tempo/internal/types"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of runs, attempts and requests
+// It does nothing unless tracing.Setup configured an exporter
+var tracer = otel.Tracer("tempo/internal/service")
+
+// Attributes of run and attempt spans
+const (
+	attrJobID       = attribute.Key("tempo.job.id")
+	attrRunID       = attribute.Key("tempo.run.id")
+	attrTrigger     = attribute.Key("tempo.trigger")
+	attrScheduledAt = attribute.Key("tempo.scheduled_at")
+	attrAttempt     = attribute.Key("tempo.attempt")
+	attrStatus      = attribute.Key("tempo.status")
+	attrQueueMs     = attribute.Key("tempo.queue_ms")
+	attrDelayMs     = attribute.Key("tempo.retry.delay_ms")
+)
+
+/*
+* endExecutionSpan records the outcome of a run or attempt on its span and ends it
+* Only failures mark the span as an error, skipped and cancelled runs are
+* told apart by their status attribute
+ */
+func endExecutionSpan(span trace.Span, exec types.Execution) {
+	span.SetAttributes(attrStatus.String(string(exec.Status)))
+	if exec.StatusCode != 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(exec.StatusCode))
+	}
+	if exec.Status == types.StatusFailure {
+		span.SetStatus(codes.Error, exec.Error)
+	}
+	span.End()
+}
+
+/*
+* requestAttributes describe an outgoing request without its URL, which may
+* hold secrets; the attempt span carries the redacted URL
+ */
+func requestAttributes(req *http.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+	}
+
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	if n, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(n))
+	}
+	return attrs
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"tempo/internal/redact"
+	"tempo/internal/types"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	traceSetup sync.Once
+	spans      = tracetest.NewInMemoryExporter()
+)
+
+// recordSpans sends the spans of the package tracer to an in-memory
+// exporter. The global provider can only be replaced once, so every test
+// shares it and starts with an empty exporter.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	traceSetup.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	spans.Reset()
+	t.Cleanup(spans.Reset)
+	return spans
+}
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Secret(name string) (string, error) {
+	return s[name], nil
+}
+
+func findSpan(t *testing.T, stubs tracetest.SpanStubs, name string, n int) tracetest.SpanStub {
+	t.Helper()
+	for _, stub := range stubs {
+		if stub.Name != name {
+			continue
+		}
+		if n == 0 {
+			return stub
+		}
+		n--
+	}
+	t.Fatalf("no span %q among %d spans", name, len(stubs))
+	return tracetest.SpanStub{}
+}
+
+func attr(stub tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range stub.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestRunSpans(t *testing.T) {
+	exporter := recordSpans(t)
+
+	const secret = "s3cr3t-path"
+	var mu sync.Mutex
+	var requests int
+	var traceparents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	s, _ := newTestScheduler(t, WithSecrets(staticSecrets{"hook": secret}))
+	job := types.Job{
+		ID:     "traced",
+		Method: "POST",
+		URL:    server.URL + `/hooks/{{ secret "hook" }}?token=abc123`,
+		Retry:  &types.RetryPolicy{MaxAttempts: 2, InitialDelay: types.Duration(time.Millisecond)},
+	}
+	exec, err := s.runJob(context.Background(), job, time.Now(), types.TriggerManual)
+	if err != nil {
+		t.Fatalf("runJob() failed: %v", err)
+	}
+
+	stubs := exporter.GetSpans()
+	if len(stubs) != 5 {
+		t.Fatalf("got %d spans, want a run, 2 attempts and 2 requests", len(stubs))
+	}
+	run := findSpan(t, stubs, "run traced", 0)
+	if run.Parent.IsValid() {
+		t.Errorf("run span has a parent")
+	}
+	for key, want := range map[attribute.Key]string{
+		attrJobID:   "traced",
+		attrTrigger: types.TriggerManual,
+		attrRunID:   exec.RunID,
+		attrStatus:  string(types.StatusSuccess),
+	} {
+		if got, _ := attr(run, key); got.AsString() != want {
+			t.Errorf("run span %s = %q, want %q", key, got.AsString(), want)
+		}
+	}
+	if len(run.Events) != 1 || run.Events[0].Name != "retry" {
+		t.Errorf("run span events = %+v, want one retry", run.Events)
+	}
+
+	for i, status := range []int{http.StatusServiceUnavailable, http.StatusOK} {
+		attempt := findSpan(t, stubs, "attempt", i)
+		if attempt.Parent.SpanID() != run.SpanContext.SpanID() {
+			t.Errorf("attempt %d is not a child of the run", i+1)
+		}
+		if got, _ := attr(attempt, attrAttempt); got.AsInt64() != int64(i+1) {
+			t.Errorf("attempt %d has %s = %d", i+1, attrAttempt, got.AsInt64())
+		}
+		if got, _ := attr(attempt, semconv.HTTPResponseStatusCodeKey); got.AsInt64() != int64(status) {
+			t.Errorf("attempt %d status code = %d, want %d", i+1, got.AsInt64(), status)
+		}
+		if got, _ := attr(attempt, semconv.URLFullKey); !strings.Contains(got.AsString(), redact.Mask) {
+			t.Errorf("attempt %d url.full = %q, want it redacted", i+1, got.AsString())
+		}
+
+		request := findSpan(t, stubs, "POST", i)
+		if request.Parent.SpanID() != attempt.SpanContext.SpanID() {
+			t.Errorf("request %d is not a child of its attempt", i+1)
+		}
+		if request.SpanKind != trace.SpanKindClient {
+			t.Errorf("request %d kind = %v, want client", i+1, request.SpanKind)
+		}
+		if got, _ := attr(request, semconv.HTTPRequestMethodKey); got.AsString() != "POST" {
+			t.Errorf("request %d method = %q", i+1, got.AsString())
+		}
+
+		// The webhook continues the trace of the request span
+		want := "00-" + request.SpanContext.TraceID().String() + "-" + request.SpanContext.SpanID().String() + "-01"
+		if traceparents[i] != want {
+			t.Errorf("request %d traceparent = %q, want %q", i+1, traceparents[i], want)
+		}
+	}
+
+	// Neither the secret nor the query token end up in a span
+	for _, stub := range stubs {
+		for _, kv := range stub.Attributes {
+			value := kv.Value.Emit()
+			if strings.Contains(value, secret) || strings.Contains(value, "abc123") {
+				t.Errorf("span %q attribute %s = %q leaks a secret", stub.Name, kv.Key, value)
+			}
+		}
+		if strings.Contains(stub.Status.Description, secret) || strings.Contains(stub.Status.Description, "abc123") {
+			t.Errorf("span %q status %q leaks a secret", stub.Name, stub.Status.Description)
+		}
+	}
+}
+
+func TestRequestSpanFailure(t *testing.T) {
+	exporter := recordSpans(t)
+
+	s, _ := newTestScheduler(t)
+	job := types.Job{ID: "down", Method: "GET", URL: "http://127.0.0.1:1/hook?api_key=abc123"}
+	if _, err := s.runJob(context.Background(), job, time.Now(), types.TriggerSchedule); err == nil {
+		t.Fatal("runJob() succeeded against a closed port")
+	}
+
+	for _, stub := range exporter.GetSpans() {
+		for _, kv := range stub.Attributes {
+			if strings.Contains(kv.Value.Emit(), "abc123") {
+				t.Errorf("span %q attribute %s leaks the query token", stub.Name, kv.Key)
+			}
+		}
+		if strings.Contains(stub.Status.Description, "abc123") {
+			t.Errorf("span %q status %q leaks the query token", stub.Name, stub.Status.Description)
+		}
+	}
+	request := findSpan(t, exporter.GetSpans(), "GET", 0)
+	if request.Status.Description != "request failed" {
+		t.Errorf("request span status = %q, want %q", request.Status.Description, "request failed")
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"tempo/internal/logging"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the bursts of file events a single save produces into one reload
+const reloadDebounce = 250 * time.Millisecond
+
+/*
+* WatchFile reloads the scheduler's jobs whenever the file at path changes
+* The parent directory is watched so atomic replaces and re-creations are seen
+* Watching stops when the scheduler is stopped
+ */
+func (s *Scheduler) WatchFile(path string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %v", err)
+	}
+
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %v", filepath.Dir(path), err)
+	}
+
+	name := filepath.Base(path)
+	go func() {
+		defer watcher.Close()
+
+		var debounce <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != name || event.Op == fsnotify.Chmod {
+					continue
+				}
+				debounce = time.After(reloadDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.log.Error("Failed to watch job file", "path", path, logging.Err(err))
+			case <-debounce:
+				debounce = nil
+				s.ReloadAndLog(fmt.Sprintf("%s changed", name))
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+/*
+* ReloadAndLog reloads the jobs and logs what changed and why
+* It is meant for background triggers such as file changes and SIGHUP
+ */
+func (s *Scheduler) ReloadAndLog(reason string) {
+	summary, err := s.Reload()
+	if err != nil {
+		s.log.Error("Failed to reload jobs", "reason", reason, logging.Err(err))
+		return
+	}
+
+	s.log.Info("Reloaded jobs", "reason", reason,
+		"added", len(summary.Added), "updated", len(summary.Updated), "removed", len(summary.Removed))
+}
@@ -0,0 +1,526 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"tempo/internal/config"
+	"tempo/internal/types"
+	"time"
+)
+
+// Defaults of the execution history
+const (
+	HistorySegmentSize   = 8 << 20 // bytes
+	DefaultHistoryMaxAge = 90 * 24 * time.Hour
+)
+
+const (
+	historyFile      = "history.jsonl"
+	historyIndexFile = "history.index.json"
+	historyPrefix    = "history-"
+	historySuffix    = ".jsonl"
+)
+
+// History is an execution log stored as JSON lines in the data directory.
+// Executions are appended to history.jsonl. Once it grows beyond the segment
+// size it is sealed as history-<time>.jsonl and a new file is started.
+// history.index.json describes the sealed segments and keeps per-job totals,
+// so summaries only read the current file and queries skip segments outside
+// their time range. Sealed segments beyond the retention are removed.
+type History struct {
+	dir         string
+	segmentSize int64
+	maxAge      time.Duration
+	maxRecords  int
+	mutex       sync.Mutex
+}
+
+// HistoryFilter narrows down the executions returned by Query.
+// Zero values disable the corresponding filter.
+type HistoryFilter struct {
+	JobID string
+	Since time.Time
+	Limit int
+}
+
+// JobHistory summarizes the recorded executions of a job
+type JobHistory struct {
+	Runs        int64     `json:"runs"`                   // first attempts, retries belong to their run
+	LastSuccess time.Time `json:"last_success,omitempty"` // when the last successful execution finished
+}
+
+// historyIndex describes the sealed segments of the history
+type historyIndex struct {
+	Segments []historySegment `json:"segments"`
+	// Totals of every segment sealed so far, including removed ones, so run
+	// counters never go backwards
+	Jobs map[string]JobHistory `json:"jobs"`
+}
+
+type historySegment struct {
+	File    string    `json:"file"`
+	First   time.Time `json:"first"` // earliest start of an execution in the segment
+	Last    time.Time `json:"last"`  // latest start of an execution in the segment
+	Records int       `json:"records"`
+}
+
+func NewHistory(dataDir string, retention *config.History) (*History, error) {
+	dataDir, err := DataDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &History{
+		dir:         dataDir,
+		segmentSize: HistorySegmentSize,
+		maxAge:      DefaultHistoryMaxAge,
+	}
+	if retention != nil {
+		if retention.MaxAge > 0 {
+			h.maxAge = time.Duration(retention.MaxAge)
+		}
+		h.maxRecords = retention.MaxRecords
+	}
+	return h, nil
+}
+
+// Record appends an execution to the history file and syncs it to disk,
+// sealing the file as a segment once it is full
+func (h *History) Record(exec types.Execution) error {
+	data, err := json.Marshal(exec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution: %v", err)
+	}
+	data = append(data, '\n')
+
+	return h.append(data)
+}
+
+// RecordAll appends several executions with a single write
+func (h *History) RecordAll(execs []types.Execution) error {
+	var data []byte
+	for _, exec := range execs {
+		line, err := json.Marshal(exec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal execution: %v", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	return h.append(data)
+}
+
+func (h *History) append(data []byte) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// Other processes record executions too, and must not append to a file
+	// while it is sealed
+	unlock, err := LockFile(h.path(historyFile) + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock history file: %v", err)
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(h.path(historyFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+	if info.Size() < h.segmentSize {
+		return nil
+	}
+	if err := h.seal(); err != nil {
+		return fmt.Errorf("failed to rotate history file: %v", err)
+	}
+	return nil
+}
+
+// Query returns the executions matching the filter, oldest first.
+// When a limit is set only the most recent matches are returned.
+func (h *History) Query(filter HistoryFilter) ([]types.Execution, error) {
+	var executions []types.Execution
+
+	err := h.read(func(index historyIndex, segments []string) error {
+		executions = nil
+
+		// Newest first, so a limit stops before reading old segments
+		files := append(slices.Clone(segments), historyFile)
+		for i := len(files) - 1; i >= 0; i-- {
+			if seg, ok := index.segment(files[i]); ok && !filter.Since.IsZero() && seg.Last.Before(filter.Since) {
+				continue
+			}
+
+			var matches []types.Execution
+			err := scanHistoryFile(h.path(files[i]), func(exec types.Execution) {
+				if !filter.matches(exec) {
+					return
+				}
+				matches = append(matches, exec)
+				if filter.Limit > 0 && len(matches) > filter.Limit {
+					matches = matches[1:]
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			executions = append(matches, executions...)
+			if filter.Limit > 0 && len(executions) >= filter.Limit {
+				executions = executions[len(executions)-filter.Limit:]
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// Summarize returns the run count and last success of the job, or of every
+// job when jobID is empty. Only the current file is read, sealed segments
+// are taken from the index.
+func (h *History) Summarize(jobID string) (map[string]JobHistory, error) {
+	var jobs map[string]JobHistory
+
+	err := h.read(func(index historyIndex, segments []string) error {
+		jobs = make(map[string]JobHistory)
+		for id, job := range index.Jobs {
+			if jobID == "" || id == jobID {
+				jobs[id] = job
+			}
+		}
+
+		// Segments missing from the index were sealed by a process that
+		// crashed before updating it
+		files := []string{historyFile}
+		for _, file := range segments {
+			if _, ok := index.segment(file); !ok {
+				files = append(files, file)
+			}
+		}
+		for _, file := range files {
+			err := scanHistoryFile(h.path(file), func(exec types.Execution) {
+				if jobID == "" || exec.JobID == jobID {
+					job := jobs[exec.JobID]
+					job.add(exec)
+					jobs[exec.JobID] = job
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// read runs fn on a consistent view of the history. Another process may seal
+// the current file while it is read, in which case fn runs again.
+func (h *History) read(fn func(index historyIndex, segments []string) error) error {
+	for {
+		index, err := h.readIndex()
+		if err != nil {
+			return err
+		}
+		segments, err := h.segments()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(index, segments); err != nil {
+			return err
+		}
+
+		after, err := h.segments()
+		if err != nil {
+			return err
+		}
+		if slices.Equal(segments, after) {
+			return nil
+		}
+	}
+}
+
+// seal turns the current file into a segment, records it in the index and
+// removes segments beyond the retention. The caller must hold the history
+// file lock.
+func (h *History) seal() error {
+	index, err := h.readIndex()
+	if err != nil {
+		return err
+	}
+	if err := h.indexOrphans(&index); err != nil {
+		return err
+	}
+
+	segment, jobs, err := summarizeHistoryFile(h.path(historyFile))
+	if err != nil {
+		return err
+	}
+	if segment.Records > 0 {
+		segment.File = historyPrefix + time.Now().UTC().Format("20060102T150405.000000000Z") + historySuffix
+		if err := os.Rename(h.path(historyFile), h.path(segment.File)); err != nil {
+			return err
+		}
+		index.add(segment, jobs)
+	}
+
+	// Remove expired segments before the index stops listing them, so
+	// readers never mistake one for a segment missing from the index
+	for _, file := range h.expire(&index, time.Now()) {
+		if err := os.Remove(h.path(file)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return h.writeIndex(index)
+}
+
+// indexOrphans adds segments that were sealed without updating the index
+func (h *History) indexOrphans(index *historyIndex) error {
+	segments, err := h.segments()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range segments {
+		if _, ok := index.segment(file); ok {
+			continue
+		}
+		segment, jobs, err := summarizeHistoryFile(h.path(file))
+		if err != nil {
+			return err
+		}
+		segment.File = file
+		index.add(segment, jobs)
+	}
+
+	sort.Slice(index.Segments, func(i, j int) bool {
+		return index.Segments[i].File < index.Segments[j].File
+	})
+	return nil
+}
+
+// expire drops the oldest segments from the index while they are older than
+// the maximum age or the newer ones hold enough records, and returns them
+func (h *History) expire(index *historyIndex, now time.Time) []string {
+	total := 0
+	for _, seg := range index.Segments {
+		total += seg.Records
+	}
+
+	var removed []string
+	for len(index.Segments) > 0 {
+		oldest := index.Segments[0]
+		expired := h.maxAge > 0 && oldest.Last.Before(now.Add(-h.maxAge))
+		excess := h.maxRecords > 0 && total-oldest.Records >= h.maxRecords
+		if !expired && !excess {
+			break
+		}
+
+		removed = append(removed, oldest.File)
+		total -= oldest.Records
+		index.Segments = index.Segments[1:]
+	}
+	return removed
+}
+
+// segments lists the sealed segment files, oldest first
+func (h *History) segments() ([]string, error) {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history files: %v", err)
+	}
+
+	var segments []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, historyPrefix) && strings.HasSuffix(name, historySuffix) {
+			segments = append(segments, name)
+		}
+	}
+	// Names carry the sealing time, so they sort chronologically
+	sort.Strings(segments)
+	return segments, nil
+}
+
+func (h *History) readIndex() (historyIndex, error) {
+	index := historyIndex{Jobs: make(map[string]JobHistory)}
+
+	data, err := os.ReadFile(h.path(historyIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return index, fmt.Errorf("failed to read history index: %v", err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("failed to parse history index: %v", err)
+	}
+	if index.Jobs == nil {
+		index.Jobs = make(map[string]JobHistory)
+	}
+	return index, nil
+}
+
+func (h *History) writeIndex(index historyIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history index: %v", err)
+	}
+	if err := WriteFileAtomic(h.path(historyIndexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write history index: %v", err)
+	}
+	return nil
+}
+
+func (h *History) path(name string) string {
+	return filepath.Join(h.dir, name)
+}
+
+func (i historyIndex) segment(file string) (historySegment, bool) {
+	for _, seg := range i.Segments {
+		if seg.File == file {
+			return seg, true
+		}
+	}
+	return historySegment{}, false
+}
+
+func (i *historyIndex) add(segment historySegment, jobs map[string]JobHistory) {
+	i.Segments = append(i.Segments, segment)
+	for id, summary := range jobs {
+		job := i.Jobs[id]
+		job.merge(summary)
+		i.Jobs[id] = job
+	}
+}
+
+func (j *JobHistory) add(exec types.Execution) {
+	if exec.Attempt <= 1 {
+		j.Runs++
+	}
+	if exec.Status == types.StatusSuccess && exec.FinishedAt.After(j.LastSuccess) {
+		j.LastSuccess = exec.FinishedAt
+	}
+}
+
+func (j *JobHistory) merge(other JobHistory) {
+	j.Runs += other.Runs
+	if other.LastSuccess.After(j.LastSuccess) {
+		j.LastSuccess = other.LastSuccess
+	}
+}
+
+func (f HistoryFilter) matches(exec types.Execution) bool {
+	if f.JobID != "" && exec.JobID != f.JobID {
+		return false
+	}
+	if !f.Since.IsZero() && exec.StartedAt.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// summarizeHistoryFile describes the executions in a history file
+func summarizeHistoryFile(path string) (historySegment, map[string]JobHistory, error) {
+	var segment historySegment
+	jobs := make(map[string]JobHistory)
+
+	err := scanHistoryFile(path, func(exec types.Execution) {
+		if segment.Records == 0 || exec.StartedAt.Before(segment.First) {
+			segment.First = exec.StartedAt
+		}
+		if exec.StartedAt.After(segment.Last) {
+			segment.Last = exec.StartedAt
+		}
+		segment.Records++
+
+		job := jobs[exec.JobID]
+		job.add(exec)
+		jobs[exec.JobID] = job
+	})
+	return segment, jobs, err
+}
+
+// maxHistoryLine bounds the size of an execution read back from a history
+// file. Longer lines are skipped, so one huge record can't fail every query.
+const maxHistoryLine = 1 << 20
+
+// scanHistoryFile calls fn for every execution in a history file, oldest
+// first. A missing file holds no executions.
+func scanHistoryFile(path string, fn func(exec types.Execution)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Nothing has been executed yet, or the segment expired
+			return nil
+		}
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	var line []byte
+	oversized := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !oversized && len(line)+len(chunk) > maxHistoryLine {
+			oversized = true
+			line = line[:0]
+		}
+		if !oversized {
+			line = append(line, chunk...)
+		}
+		if err == bufio.ErrBufferFull {
+			// The rest of the line follows in the next chunk
+			continue
+		}
+
+		var exec types.Execution
+		// Skip lines torn by a crash mid-write, and oversized ones
+		if !oversized && json.Unmarshal(line, &exec) == nil {
+			fn(exec)
+		}
+		line = line[:0]
+		oversized = false
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read history file: %v", err)
+		}
+	}
+}
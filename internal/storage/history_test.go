@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"tempo/internal/config"
+	"tempo/internal/types"
+	"testing"
+	"time"
+)
+
+// historyStart is recent, so the default maximum age keeps every test execution
+var historyStart = time.Now().UTC().Truncate(time.Minute).Add(-time.Hour)
+
+// newTestHistory returns a history that seals the current file after every write
+func newTestHistory(t *testing.T, retention *config.History) *History {
+	t.Helper()
+	h, err := NewHistory(t.TempDir(), retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.segmentSize = 1
+	return h
+}
+
+// execution returns the n-th successful first attempt of a job, one minute apart
+func execution(jobID string, n int) types.Execution {
+	started := historyStart.Add(time.Duration(n) * time.Minute)
+	return types.Execution{
+		ID:         fmt.Sprintf("%s-%d", jobID, n),
+		JobID:      jobID,
+		Attempt:    1,
+		StartedAt:  started,
+		FinishedAt: started.Add(time.Second),
+		Status:     types.StatusSuccess,
+	}
+}
+
+func record(t *testing.T, h *History, execs ...types.Execution) {
+	t.Helper()
+	for _, exec := range execs {
+		if err := h.Record(exec); err != nil {
+			t.Fatalf("Record(%s) failed: %v", exec.ID, err)
+		}
+	}
+}
+
+func ids(execs []types.Execution) []string {
+	var ids []string
+	for _, exec := range execs {
+		ids = append(ids, exec.ID)
+	}
+	return ids
+}
+
+func TestHistorySegments(t *testing.T) {
+	h := newTestHistory(t, nil)
+	retry := execution("a", 2)
+	retry.ID, retry.Attempt, retry.Status = "a-2-retry", 2, types.StatusFailure
+	record(t, h, execution("a", 1), execution("a", 2), retry, execution("b", 3))
+
+	segments, err := h.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 4 {
+		t.Fatalf("got %d segments, want one per write: %v", len(segments), segments)
+	}
+	if _, err := os.Stat(h.path(historyFile)); !os.IsNotExist(err) {
+		t.Errorf("current history file still exists after sealing: %v", err)
+	}
+
+	index, err := h.readIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range segments {
+		seg, ok := index.segment(file)
+		if !ok || seg.Records != 1 {
+			t.Errorf("index entry of %s = %+v, %v, want one record", file, seg, ok)
+		}
+	}
+
+	got, err := h.Query(HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a-1", "a-2", "a-2-retry", "b-3"}; !slices.Equal(ids(got), want) {
+		t.Errorf("Query() = %v, want %v", ids(got), want)
+	}
+
+	summary, err := h.Summarize("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := summary["a"]; a.Runs != 2 || !a.LastSuccess.Equal(execution("a", 2).FinishedAt) {
+		t.Errorf("summary of a = %+v, want 2 runs and the last success of a-2", a)
+	}
+	if b := summary["b"]; b.Runs != 1 {
+		t.Errorf("summary of b = %+v, want 1 run", b)
+	}
+}
+
+func TestHistorySummarizeOrphanSegment(t *testing.T) {
+	h := newTestHistory(t, nil)
+	record(t, h, execution("a", 1))
+
+	// A process that crashed after sealing never updated the index
+	if err := os.Remove(h.path(historyIndexFile)); err != nil {
+		t.Fatal(err)
+	}
+	h.segmentSize = HistorySegmentSize
+	record(t, h, execution("a", 2))
+
+	summary, err := h.Summarize("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := summary["a"].Runs; runs != 2 {
+		t.Errorf("runs = %d, want the orphan segment and the current file counted", runs)
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	t.Run("max records", func(t *testing.T) {
+		h := newTestHistory(t, &config.History{MaxRecords: 2})
+		for n := range 5 {
+			record(t, h, execution("a", n))
+		}
+
+		segments, err := h.segments()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) != 2 {
+			t.Errorf("kept %d segments, want 2", len(segments))
+		}
+		got, err := h.Query(HistoryFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a-3", "a-4"}; !slices.Equal(ids(got), want) {
+			t.Errorf("Query() = %v, want %v", ids(got), want)
+		}
+
+		// Totals include the removed segments
+		summary, err := h.Summarize("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if runs := summary["a"].Runs; runs != 5 {
+			t.Errorf("runs = %d, want 5", runs)
+		}
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		h := newTestHistory(t, &config.History{MaxAge: types.Duration(24 * time.Hour)})
+		old := execution("a", 0)
+		old.StartedAt = time.Now().Add(-48 * time.Hour)
+		recent := execution("a", 1)
+		recent.StartedAt = time.Now().Add(-time.Hour)
+		record(t, h, old, recent)
+
+		got, err := h.Query(HistoryFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a-1"}; !slices.Equal(ids(got), want) {
+			t.Errorf("Query() = %v, want %v", ids(got), want)
+		}
+	})
+}
+
+func TestHistoryQuery(t *testing.T) {
+	h := newTestHistory(t, nil)
+	// Three sealed segments, then three executions in the current file
+	record(t, h, execution("a", 0), execution("b", 1), execution("a", 2))
+	h.segmentSize = HistorySegmentSize
+	record(t, h, execution("b", 3), execution("a", 4), execution("a", 5))
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{"everything", HistoryFilter{}, []string{"a-0", "b-1", "a-2", "b-3", "a-4", "a-5"}},
+		{"job", HistoryFilter{JobID: "b"}, []string{"b-1", "b-3"}},
+		{"limit within the current file", HistoryFilter{Limit: 2}, []string{"a-4", "a-5"}},
+		{"limit across segments", HistoryFilter{Limit: 4}, []string{"a-2", "b-3", "a-4", "a-5"}},
+		{"job and limit", HistoryFilter{JobID: "a", Limit: 3}, []string{"a-2", "a-4", "a-5"}},
+		{"since", HistoryFilter{Since: historyStart.Add(2 * time.Minute)}, []string{"a-2", "b-3", "a-4", "a-5"}},
+		{"since and limit", HistoryFilter{Since: historyStart.Add(time.Minute), Limit: 10}, []string{"b-1", "a-2", "b-3", "a-4", "a-5"}},
+		{"since after everything", HistoryFilter{Since: historyStart.Add(time.Hour)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(ids(got), tt.want) {
+				t.Errorf("Query(%+v) = %v, want %v", tt.filter, ids(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestHistorySkipsUnreadableLines(t *testing.T) {
+	h, err := NewHistory(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record(t, h, execution("a", 1))
+
+	huge := execution("a", 2)
+	huge.Error = strings.Repeat("x", 2*maxHistoryLine)
+	record(t, h, huge)
+
+	// A line torn by a crash mid-write
+	file, err := os.OpenFile(h.path(historyFile), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"id": "torn", "job_`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	got, err := h.Query(HistoryFilter{})
+	if err != nil {
+		t.Fatalf("Query() failed: %v", err)
+	}
+	if want := []string{"a-1"}; !slices.Equal(ids(got), want) {
+		t.Errorf("Query() = %v, want %v", ids(got), want)
+	}
+	summary, err := h.Summarize("a")
+	if err != nil {
+		t.Fatalf("Summarize() failed: %v", err)
+	}
+	if runs := summary["a"].Runs; runs != 1 {
+		t.Errorf("runs = %d, want 1", runs)
+	}
+}
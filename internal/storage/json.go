@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"tempo/internal/config"
+	"tempo/internal/types"
+)
+
+// JSONStorage keeps jobs in a jobs.json file and execution history in history.jsonl
+type JSONStorage struct {
+	filepath string
+	mutex    sync.RWMutex
+	jobs     map[string]types.Job
+	history  *History
+}
+
+func NewJSONStorage(dataDir string, retention *config.History) (*JSONStorage, error) {
+	dataDir, err := DataDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := NewHistory(dataDir, retention)
+	if err != nil {
+		return nil, err
+	}
+
+	filepath := filepath.Join(dataDir, "jobs.json")
+	storage := &JSONStorage{
+		filepath: filepath,
+		jobs:     make(map[string]types.Job),
+		history:  history,
+	}
+
+	// Load existing jobs
+	if err := storage.load(); err != nil {
+		return nil, fmt.Errorf("failed to load jobs: %v", err)
+	}
+
+	return storage, nil
+}
+
+// Path returns the location of the jobs file
+func (s *JSONStorage) Path() string {
+	return s.filepath
+}
+
+func (s *JSONStorage) AddJob(job types.Job) error {
+	return s.update(func() error {
+		s.jobs[job.ID] = job
+		return nil
+	})
+}
+
+func (s *JSONStorage) UpdateJob(id string, change func(job types.Job, exists bool) (types.Job, error)) error {
+	err := s.update(func() error {
+		current, exists := s.jobs[id]
+		job, err := change(current, exists)
+		if err != nil {
+			return err
+		}
+		s.jobs[job.ID] = job
+		return nil
+	})
+	if errors.Is(err, ErrJobUnchanged) {
+		return nil
+	}
+	return err
+}
+
+func (s *JSONStorage) GetJob(id string) (types.Job, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	job, exists := s.jobs[id]
+	return job, exists
+}
+
+func (s *JSONStorage) GetAllJobs() []types.Job {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	jobs := make([]types.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func (s *JSONStorage) RemoveJob(id string) error {
+	return s.update(func() error {
+		if _, exists := s.jobs[id]; !exists {
+			return fmt.Errorf("job '%s' not found", id)
+		}
+
+		delete(s.jobs, id)
+		return nil
+	})
+}
+
+func (s *JSONStorage) RemoveAllJobs() error {
+	return s.update(func() error {
+		s.jobs = make(map[string]types.Job)
+		return nil
+	})
+}
+
+// Reload discards the in-memory jobs and re-reads them from disk
+func (s *JSONStorage) Reload() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.load()
+}
+
+// update applies a change to the jobs while holding the jobs file lock.
+// The file is re-read under the lock first, so changes made by other
+// processes since this storage was opened are never lost.
+func (s *JSONStorage) update(change func() error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	unlock, err := LockFile(s.filepath + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock jobs file: %v", err)
+	}
+	defer unlock()
+
+	if err := s.load(); err != nil {
+		return fmt.Errorf("failed to load jobs: %v", err)
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+func (s *JSONStorage) RecordExecution(exec types.Execution) error {
+	return s.history.Record(exec)
+}
+
+// Import adds the jobs with a single write of the jobs file, then appends the
+// executions to the history with a single write
+func (s *JSONStorage) Import(jobs []types.Job, executions []types.Execution) error {
+	err := s.update(func() error {
+		for _, job := range jobs {
+			s.jobs[job.ID] = job
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.history.RecordAll(executions)
+}
+
+func (s *JSONStorage) QueryExecutions(filter HistoryFilter) ([]types.Execution, error) {
+	return s.history.Query(filter)
+}
+
+func (s *JSONStorage) SummarizeExecutions(jobID string) (map[string]JobHistory, error) {
+	return s.history.Summarize(jobID)
+}
+
+func (s *JSONStorage) Close() error {
+	return nil
+}
+
+func (s *JSONStorage) load() error {
+	data, err := os.ReadFile(s.filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist yet, that's okay
+			s.jobs = make(map[string]types.Job)
+			return nil
+		}
+		return err
+	}
+
+	var jobs []types.Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return fmt.Errorf("failed to unmarshal jobs: %v", err)
+	}
+
+	// Convert slice to map
+	s.jobs = make(map[string]types.Job)
+	for _, job := range jobs {
+		s.jobs[job.ID] = job
+	}
+
+	return nil
+}
+
+func (s *JSONStorage) save() error {
+	// Convert map to slice
+	jobs := make([]types.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal jobs: %v", err)
+	}
+
+	if err := WriteFileAtomic(s.filepath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write jobs file: %v", err)
+	}
+
+	return nil
+}
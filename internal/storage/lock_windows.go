@@ -0,0 +1,36 @@
+//go:build windows
+
+package storage
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// LockFile takes an exclusive lock on the file at path, creating it if
+// needed and blocking until the lock is available. The returned function
+// releases the lock.
+func LockFile(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	handle := windows.Handle(file.Fd())
+	overlapped := &windows.Overlapped{}
+	if err := windows.LockFileEx(handle, windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		windows.UnlockFileEx(handle, 0, 1, 0, overlapped)
+		file.Close()
+	}, nil
+}
+
+// syncDir is a no-op on Windows, where directories cannot be synced
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package storage
+
+import "fmt"
+
+// MigrationResult counts what was copied by Migrate
+type MigrationResult struct {
+	Jobs       int
+	Executions int
+}
+
+// Migrate copies every job and recorded execution from one storage backend to another.
+// Existing jobs in the target with the same ID are overwritten.
+func Migrate(from, to Storage) (MigrationResult, error) {
+	jobs := from.GetAllJobs()
+
+	executions, err := from.QueryExecutions(HistoryFilter{})
+	if err != nil {
+		return MigrationResult{}, fmt.Errorf("failed to read execution history: %v", err)
+	}
+
+	if err := to.Import(jobs, executions); err != nil {
+		return MigrationResult{}, fmt.Errorf("failed to migrate: %v", err)
+	}
+
+	return MigrationResult{Jobs: len(jobs), Executions: len(executions)}, nil
+}
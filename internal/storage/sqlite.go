@@ -0,0 +1,503 @@
+//go:build cgo
+
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"tempo/internal/config"
+	"tempo/internal/types"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteFileName is the database file used by the SQLite backend
+const SQLiteFileName = "tempo.db"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS executions (
+	id         TEXT PRIMARY KEY,
+	job_id     TEXT NOT NULL,
+	started_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS executions_job_started ON executions (job_id, started_at);
+CREATE INDEX IF NOT EXISTS executions_started ON executions (started_at);
+
+CREATE TABLE IF NOT EXISTS job_history (
+	job_id       TEXT PRIMARY KEY,
+	runs         INTEGER NOT NULL,
+	last_success INTEGER NOT NULL
+);
+`
+
+// sqlitePruneInterval is how often executions beyond the retention are deleted
+const sqlitePruneInterval = time.Hour
+
+// SQLiteStorage keeps jobs and execution history in a SQLite database.
+// Every write touches a single row, so concurrent CLI invocations and the
+// scheduler never overwrite each other's changes.
+type SQLiteStorage struct {
+	db    *sql.DB
+	mutex sync.RWMutex
+	jobs  map[string]types.Job
+
+	maxAge     time.Duration
+	maxRecords int
+	pruneMutex sync.Mutex
+	lastPrune  time.Time
+}
+
+func NewSQLiteStorage(dataDir string, retention *config.History) (*SQLiteStorage, error) {
+	dataDir, err := DataDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_txlock=immediate&_journal_mode=WAL&_synchronous=NORMAL", filepath.Join(dataDir, SQLiteFileName))
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	var summarized int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'job_history'`).Scan(&summarized); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read database schema: %v", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create database schema: %v", err)
+	}
+
+	storage := &SQLiteStorage{
+		db:     db,
+		jobs:   make(map[string]types.Job),
+		maxAge: DefaultHistoryMaxAge,
+	}
+	if retention != nil {
+		if retention.MaxAge > 0 {
+			storage.maxAge = time.Duration(retention.MaxAge)
+		}
+		storage.maxRecords = retention.MaxRecords
+	}
+
+	// Databases created before job_history existed only have executions
+	if summarized == 0 {
+		if err := storage.summarizeAll(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	if err := storage.Reload(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load jobs: %v", err)
+	}
+
+	return storage, nil
+}
+
+func (s *SQLiteStorage) AddJob(job types.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.db.Exec(`INSERT INTO jobs (id, data) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, job.ID, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save job: %v", err)
+	}
+
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// UpdateJob reads and writes the job in one transaction. Transactions take
+// the write lock when they begin, so no other process can change the job in
+// between.
+func (s *SQLiteStorage) UpdateJob(id string, change func(job types.Job, exists bool) (types.Job, error)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var current types.Job
+	var data string
+	err = tx.QueryRow(`SELECT data FROM jobs WHERE id = ?`, id).Scan(&data)
+	exists := err == nil
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("failed to read job: %v", err)
+	default:
+		if err := json.Unmarshal([]byte(data), &current); err != nil {
+			return fmt.Errorf("failed to unmarshal job: %v", err)
+		}
+	}
+
+	job, err := change(current, exists)
+	if errors.Is(err, ErrJobUnchanged) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	updated, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %v", err)
+	}
+	_, err = tx.Exec(`INSERT INTO jobs (id, data) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, job.ID, string(updated))
+	if err != nil {
+		return fmt.Errorf("failed to save job: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save job: %v", err)
+	}
+
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *SQLiteStorage) GetJob(id string) (types.Job, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	job, exists := s.jobs[id]
+	return job, exists
+}
+
+func (s *SQLiteStorage) GetAllJobs() []types.Job {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	jobs := make([]types.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func (s *SQLiteStorage) RemoveJob(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM jobs WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %v", err)
+	}
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		return fmt.Errorf("job '%s' not found", id)
+	}
+
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *SQLiteStorage) RemoveAllJobs() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.db.Exec(`DELETE FROM jobs`); err != nil {
+		return fmt.Errorf("failed to delete jobs: %v", err)
+	}
+
+	s.jobs = make(map[string]types.Job)
+	return nil
+}
+
+func (s *SQLiteStorage) Reload() error {
+	rows, err := s.db.Query(`SELECT data FROM jobs`)
+	if err != nil {
+		return fmt.Errorf("failed to query jobs: %v", err)
+	}
+	defer rows.Close()
+
+	jobs := make(map[string]types.Job)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return fmt.Errorf("failed to read job: %v", err)
+		}
+
+		var job types.Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			return fmt.Errorf("failed to unmarshal job: %v", err)
+		}
+		jobs[job.ID] = job
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query jobs: %v", err)
+	}
+
+	s.mutex.Lock()
+	s.jobs = jobs
+	s.mutex.Unlock()
+
+	return nil
+}
+
+func (s *SQLiteStorage) RecordExecution(exec types.Execution) error {
+	data, err := json.Marshal(exec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution: %v", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record execution: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO executions (id, job_id, started_at, data) VALUES (?, ?, ?, ?)`,
+		exec.ID, exec.JobID, exec.StartedAt.UnixNano(), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record execution: %v", err)
+	}
+
+	var summary JobHistory
+	summary.add(exec)
+	if err := addJobHistory(tx, exec.JobID, summary); err != nil {
+		return fmt.Errorf("failed to record execution: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record execution: %v", err)
+	}
+
+	return s.prune()
+}
+
+// Import adds the jobs and executions in a single transaction
+func (s *SQLiteStorage) Import(jobs []types.Job, executions []types.Execution) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, job := range jobs {
+		data, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job: %v", err)
+		}
+		_, err = tx.Exec(`INSERT INTO jobs (id, data) VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET data = excluded.data`, job.ID, string(data))
+		if err != nil {
+			return fmt.Errorf("failed to save job '%s': %v", job.ID, err)
+		}
+	}
+
+	summaries := make(map[string]JobHistory)
+	for _, exec := range executions {
+		data, err := json.Marshal(exec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal execution: %v", err)
+		}
+		_, err = tx.Exec(`INSERT OR REPLACE INTO executions (id, job_id, started_at, data) VALUES (?, ?, ?, ?)`,
+			exec.ID, exec.JobID, exec.StartedAt.UnixNano(), string(data))
+		if err != nil {
+			return fmt.Errorf("failed to record execution '%s': %v", exec.ID, err)
+		}
+
+		summary := summaries[exec.JobID]
+		summary.add(exec)
+		summaries[exec.JobID] = summary
+	}
+	for id, summary := range summaries {
+		if err := addJobHistory(tx, id, summary); err != nil {
+			return fmt.Errorf("failed to summarize job history: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	for _, job := range jobs {
+		s.jobs[job.ID] = job
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryExecutions(filter HistoryFilter) ([]types.Execution, error) {
+	query := `SELECT data FROM executions WHERE 1 = 1`
+	var args []interface{}
+
+	if filter.JobID != "" {
+		query += ` AND job_id = ?`
+		args = append(args, filter.JobID)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND started_at >= ?`
+		args = append(args, filter.Since.UnixNano())
+	}
+
+	// Take the most recent matches, then return them oldest first
+	query += ` ORDER BY started_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query executions: %v", err)
+	}
+	defer rows.Close()
+
+	var executions []types.Execution
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read execution: %v", err)
+		}
+
+		var exec types.Execution
+		if err := json.Unmarshal([]byte(data), &exec); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal execution: %v", err)
+		}
+		executions = append(executions, exec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query executions: %v", err)
+	}
+
+	for i, j := 0, len(executions)-1; i < j; i, j = i+1, j-1 {
+		executions[i], executions[j] = executions[j], executions[i]
+	}
+
+	return executions, nil
+}
+
+func (s *SQLiteStorage) SummarizeExecutions(jobID string) (map[string]JobHistory, error) {
+	query := `SELECT job_id, runs, last_success FROM job_history`
+	var args []interface{}
+	if jobID != "" {
+		query += ` WHERE job_id = ?`
+		args = append(args, jobID)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job history: %v", err)
+	}
+	defer rows.Close()
+
+	jobs := make(map[string]JobHistory)
+	for rows.Next() {
+		var id string
+		var summary JobHistory
+		var lastSuccess int64
+		if err := rows.Scan(&id, &summary.Runs, &lastSuccess); err != nil {
+			return nil, fmt.Errorf("failed to read job history: %v", err)
+		}
+		if lastSuccess != 0 {
+			summary.LastSuccess = time.Unix(0, lastSuccess)
+		}
+		jobs[id] = summary
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query job history: %v", err)
+	}
+
+	return jobs, nil
+}
+
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// summarizeAll fills job_history from the recorded executions
+func (s *SQLiteStorage) summarizeAll() error {
+	executions, err := s.QueryExecutions(HistoryFilter{})
+	if err != nil {
+		return err
+	}
+
+	jobs := make(map[string]JobHistory)
+	for _, exec := range executions {
+		summary := jobs[exec.JobID]
+		summary.add(exec)
+		jobs[exec.JobID] = summary
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to summarize job history: %v", err)
+	}
+	defer tx.Rollback()
+
+	for id, summary := range jobs {
+		if err := addJobHistory(tx, id, summary); err != nil {
+			return fmt.Errorf("failed to summarize job history: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// prune deletes executions beyond the retention, at most once per interval.
+// Run counts live in job_history and are kept.
+func (s *SQLiteStorage) prune() error {
+	s.pruneMutex.Lock()
+	defer s.pruneMutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPrune) < sqlitePruneInterval {
+		return nil
+	}
+	s.lastPrune = now
+
+	if s.maxAge > 0 {
+		if _, err := s.db.Exec(`DELETE FROM executions WHERE started_at < ?`, now.Add(-s.maxAge).UnixNano()); err != nil {
+			return fmt.Errorf("failed to delete old executions: %v", err)
+		}
+	}
+	if s.maxRecords > 0 {
+		_, err := s.db.Exec(`DELETE FROM executions WHERE id IN
+			(SELECT id FROM executions ORDER BY started_at DESC LIMIT -1 OFFSET ?)`, s.maxRecords)
+		if err != nil {
+			return fmt.Errorf("failed to delete old executions: %v", err)
+		}
+	}
+	return nil
+}
+
+// addJobHistory adds a summary of new executions to the job's totals
+func addJobHistory(tx *sql.Tx, jobID string, summary JobHistory) error {
+	var lastSuccess int64
+	if !summary.LastSuccess.IsZero() {
+		lastSuccess = summary.LastSuccess.UnixNano()
+	}
+
+	_, err := tx.Exec(`INSERT INTO job_history (job_id, runs, last_success) VALUES (?, ?, ?)
+		ON CONFLICT (job_id) DO UPDATE SET
+			runs = runs + excluded.runs,
+			last_success = MAX(last_success, excluded.last_success)`,
+		jobID, summary.Runs, lastSuccess)
+	return err
+}
@@ -0,0 +1,21 @@
+//go:build !cgo
+
+package storage
+
+import (
+	"fmt"
+	"tempo/internal/config"
+)
+
+// SQLiteFileName is the database file used by the SQLite backend
+const SQLiteFileName = "tempo.db"
+
+// SQLiteStorage is unavailable in builds without cgo, which the SQLite
+// driver needs
+type SQLiteStorage struct {
+	Storage
+}
+
+func NewSQLiteStorage(dataDir string, retention *config.History) (*SQLiteStorage, error) {
+	return nil, fmt.Errorf("the sqlite storage backend is not available: tempo was built without cgo. Rebuild it with CGO_ENABLED=1")
+}
@@ -1,134 +1,99 @@
 package storage
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sync"
+	"tempo/internal/config"
 	"tempo/internal/types"
 )
 
-type Storage struct {
-	filepath string
-	mutex    sync.RWMutex
-	jobs     map[string]types.Job
+// Storage backends selectable with --storage or the "storage" config key
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// Storage persists jobs and their execution history
+type Storage interface {
+	AddJob(job types.Job) error
+	GetJob(id string) (types.Job, bool)
+	GetAllJobs() []types.Job
+	// UpdateJob applies change to the stored version of a job and saves the
+	// result. The read and the write happen under one lock, so a change based
+	// on the current job can't overwrite one made by another process. Return
+	// ErrJobUnchanged from change to leave the job as it is.
+	UpdateJob(id string, change func(job types.Job, exists bool) (types.Job, error)) error
+	RemoveJob(id string) error
+	RemoveAllJobs() error
+	// Reload discards cached jobs and re-reads them from the backend
+	Reload() error
+
+	RecordExecution(exec types.Execution) error
+	QueryExecutions(filter HistoryFilter) ([]types.Execution, error)
+	// SummarizeExecutions returns the run count and last success of the job,
+	// or of every job when jobID is empty, without reading the whole history
+	SummarizeExecutions(jobID string) (map[string]JobHistory, error)
+	// Import adds jobs and executions in one batch, replacing jobs with the
+	// same ID. It is used to migrate between backends.
+	Import(jobs []types.Job, executions []types.Execution) error
+
+	Close() error
 }
 
-func NewStorage(dataDir string) (*Storage, error) {
+// ErrJobUnchanged is returned by an UpdateJob change that has nothing to save
+var ErrJobUnchanged = errors.New("job unchanged")
+
+// DataDir resolves the tempo data directory, defaulting to ~/.tempo,
+// and creates it if it doesn't exist
+func DataDir(dataDir string) (string, error) {
 	if dataDir == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %v", err)
+			return "", fmt.Errorf("failed to get home directory: %v", err)
 		}
 		dataDir = filepath.Join(homeDir, ".tempo")
 	}
 
 	// Create data directory if it doesn't exist
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %v", err)
-	}
-
-	filepath := filepath.Join(dataDir, "jobs.json")
-	storage := &Storage{
-		filepath: filepath,
-		jobs:     make(map[string]types.Job),
+		return "", fmt.Errorf("failed to create data directory: %v", err)
 	}
 
-	// Load existing jobs
-	if err := storage.load(); err != nil {
-		return nil, fmt.Errorf("failed to load jobs: %v", err)
-	}
-
-	return storage, nil
+	return dataDir, nil
 }
 
-func (s *Storage) AddJob(job types.Job) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.jobs[job.ID] = job
-	return s.save()
+// NewStorage opens the storage backend configured for the data directory
+func NewStorage(dataDir string) (Storage, error) {
+	return Open(dataDir, "")
 }
 
-func (s *Storage) GetJob(id string) (types.Job, bool) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	job, exists := s.jobs[id]
-	return job, exists
-}
-
-func (s *Storage) GetAllJobs() []types.Job {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	jobs := make([]types.Job, 0, len(s.jobs))
-	for _, job := range s.jobs {
-		jobs = append(jobs, job)
-	}
-	return jobs
-}
-
-func (s *Storage) RemoveJob(id string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	if _, exists := s.jobs[id]; !exists {
-		return fmt.Errorf("job '%s' not found", id)
-	}
-
-	delete(s.jobs, id)
-	return s.save()
-}
-
-func (s *Storage) RemoveAllJobs() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.jobs = make(map[string]types.Job)
-	return s.save()
-}
-
-func (s *Storage) load() error {
-	data, err := os.ReadFile(s.filepath)
+// Open opens the named storage backend. An empty backend falls back to the
+// data directory's configuration and then to the JSON file backend.
+func Open(dataDir, backend string) (Storage, error) {
+	dataDir, err := DataDir(dataDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// File doesn't exist yet, that's okay
-			return nil
-		}
-		return err
-	}
-
-	var jobs []types.Job
-	if err := json.Unmarshal(data, &jobs); err != nil {
-		return fmt.Errorf("failed to unmarshal jobs: %v", err)
+		return nil, err
 	}
 
-	// Convert slice to map
-	s.jobs = make(map[string]types.Job)
-	for _, job := range jobs {
-		s.jobs[job.ID] = job
+	cfg, err := config.Load(dataDir)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
-}
-
-func (s *Storage) save() error {
-	// Convert map to slice
-	jobs := make([]types.Job, 0, len(s.jobs))
-	for _, job := range s.jobs {
-		jobs = append(jobs, job)
+	if err := cfg.History.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid history settings in %s: %v", config.FileName, err)
 	}
-
-	data, err := json.MarshalIndent(jobs, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal jobs: %v", err)
+	if backend == "" {
+		backend = cfg.Storage
 	}
 
-	if err := os.WriteFile(s.filepath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write jobs file: %v", err)
+	switch backend {
+	case "", BackendJSON:
+		return NewJSONStorage(dataDir, cfg.History)
+	case BackendSQLite:
+		return NewSQLiteStorage(dataDir, cfg.History)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s. Use '%s' or '%s'", backend, BackendJSON, BackendSQLite)
 	}
-
-	return nil
 }
@@ -0,0 +1,141 @@
+package templating
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Funcs returns the functions available to job templates. Time functions take
+// the time as their last argument so they can be chained in pipelines:
+//
+//	{{ .Scheduled | addDate 0 0 -7 | format "2006-01-02" }}
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		// identifiers and environment
+		"uuid":   NewUUID,
+		"env":    Env,
+		"secret": noSecrets,
+
+		// date arithmetic
+		"add":          addDuration,
+		"addDate":      addDate,
+		"truncate":     truncate,
+		"startOfDay":   startOfDay,
+		"startOfWeek":  startOfWeek,
+		"startOfMonth": startOfMonth,
+		"utc":          func(t time.Time) time.Time { return t.UTC() },
+		"inZone":       inZone,
+
+		// date formatting
+		"format":  func(layout string, t time.Time) string { return t.Format(layout) },
+		"date":    func(t time.Time) string { return t.Format("2006-01-02") },
+		"iso":     func(t time.Time) string { return t.Format(time.RFC3339) },
+		"unix":    func(t time.Time) int64 { return t.Unix() },
+		"unixMs":  func(t time.Time) int64 { return t.UnixMilli() },
+		"weekday": func(t time.Time) string { return t.Weekday().String() },
+
+		// strings
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"trim":    strings.TrimSpace,
+		"default": defaultValue,
+		"json":    toJSON,
+	}
+}
+
+// NewUUID returns a random RFC 4122 version 4 UUID
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// EnvPrefix starts the names of the environment variables templates can read
+const EnvPrefix = "TEMPO_VAR_"
+
+// Env returns the value of an environment variable for {{ env "NAME" }}.
+// Only variables starting with EnvPrefix are available, so templates cannot
+// read credentials such as TEMPO_SECRET_KEY from the scheduler's environment.
+func Env(name string) (string, error) {
+	if !strings.HasPrefix(name, EnvPrefix) {
+		return "", fmt.Errorf("environment variable %s is not available to templates, only %s* variables are", name, EnvPrefix)
+	}
+	return os.Getenv(name), nil
+}
+
+// ErrNoSecrets is returned by {{ secret }} when templates are rendered
+// without access to the secrets store
+var ErrNoSecrets = errors.New("secrets are only resolved by the scheduler")
+
+func noSecrets(name string) (string, error) {
+	return "", ErrNoSecrets
+}
+
+func addDuration(duration string, t time.Time) (time.Time, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return t, err
+	}
+	return t.Add(d), nil
+}
+
+func addDate(years, months, days int, t time.Time) time.Time {
+	return t.AddDate(years, months, days)
+}
+
+func truncate(duration string, t time.Time) (time.Time, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return t, err
+	}
+	return t.Truncate(d), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns midnight of the Monday of t's week
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func inZone(name string, t time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return t, err
+	}
+	return t.In(loc), nil
+}
+
+func defaultValue(fallback, value interface{}) interface{} {
+	if value == nil {
+		return fallback
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return fallback
+	}
+	return value
+}
+
+// toJSON encodes a value as JSON, e.g. to safely embed strings in a JSON body
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
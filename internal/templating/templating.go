@@ -0,0 +1,147 @@
+package templating
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"tempo/internal/types"
+	"text/template"
+	"time"
+)
+
+// Data is what templates in a job's URL, headers and body can refer to
+type Data struct {
+	JobID     string    // {{ .JobID }}
+	RunID     string    // unique per run, shared by its retries
+	Run       int64     // sequential run number of the job, starting at 1
+	Scheduled time.Time // time the run was scheduled for
+	Now       time.Time // time the run actually started
+}
+
+// HasTemplates reports whether any part of the job's request uses template actions
+func HasTemplates(job types.Job) bool {
+	if isTemplate(job.URL) || isTemplate(job.Body) {
+		return true
+	}
+	for key, value := range job.Headers {
+		if isTemplate(key) || isTemplate(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate parses every template in the job without executing it.
+// Extra functions are merged into the default function set.
+func Validate(job types.Job, extra template.FuncMap) error {
+	funcs := mergeFuncs(extra)
+	_, err := transform(job, func(name, text string) (string, error) {
+		_, err := parse(name, text, funcs)
+		return text, err
+	})
+	return err
+}
+
+// Render returns a copy of the job with templates in its URL, header names and
+// values, and body executed against data. Extra functions are merged into the
+// default function set.
+func Render(job types.Job, data Data, extra template.FuncMap) (types.Job, error) {
+	funcs := mergeFuncs(extra)
+	return transform(job, func(name, text string) (string, error) {
+		tmpl, err := parse(name, text, funcs)
+		if err != nil {
+			return "", err
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("failed to render %s: %v", name, err)
+		}
+		return buf.String(), nil
+	})
+}
+
+// Expand executes a template outside of a job's request, such as one in an
+// alert channel's settings. Only functions are available, there is no data.
+// Extra functions are merged into the default function set.
+func Expand(name, text string, extra template.FuncMap) (string, error) {
+	if !isTemplate(text) {
+		return text, nil
+	}
+	tmpl, err := parse(name, text, mergeFuncs(extra))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", fmt.Errorf("failed to render %s: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+// ValidateText parses a template that is expanded with Expand, without executing it
+func ValidateText(name, text string, extra template.FuncMap) error {
+	if !isTemplate(text) {
+		return nil
+	}
+	_, err := parse(name, text, mergeFuncs(extra))
+	return err
+}
+
+// transform applies fn to every templated part of the job's request
+func transform(job types.Job, fn func(name, text string) (string, error)) (types.Job, error) {
+	expand := func(name, text string) (string, error) {
+		if !isTemplate(text) {
+			return text, nil
+		}
+		return fn(name, text)
+	}
+
+	result := job
+	var err error
+
+	if result.URL, err = expand("url", job.URL); err != nil {
+		return job, err
+	}
+	if result.Body, err = expand("body", job.Body); err != nil {
+		return job, err
+	}
+
+	if len(job.Headers) > 0 {
+		result.Headers = make(map[string]string, len(job.Headers))
+		for key, value := range job.Headers {
+			renderedKey, err := expand("header name "+key, key)
+			if err != nil {
+				return job, err
+			}
+			renderedValue, err := expand("header "+key, value)
+			if err != nil {
+				return job, err
+			}
+			result.Headers[renderedKey] = renderedValue
+		}
+	}
+
+	return result, nil
+}
+
+func parse(name, text string, funcs template.FuncMap) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template in %s: %v", name, err)
+	}
+	return tmpl, nil
+}
+
+func mergeFuncs(extra template.FuncMap) template.FuncMap {
+	funcs := Funcs()
+	for name, fn := range extra {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+func isTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
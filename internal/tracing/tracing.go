@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+)
+
+// ServiceName is reported unless OTEL_SERVICE_NAME or OTEL_RESOURCE_ATTRIBUTES set another
+const ServiceName = "tempo"
+
+// Enabled reports whether the environment asks for traces to be exported:
+// an OTLP endpoint or OTEL_TRACES_EXPORTER=otlp is set, and neither
+// OTEL_SDK_DISABLED=true nor OTEL_TRACES_EXPORTER=none
+func Enabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "none":
+		return false
+	case "otlp":
+		return true
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup exports spans over OTLP as configured by the standard OTEL_*
+// environment variables and propagates W3C trace context in outgoing
+// requests. It returns a function that flushes pending spans and stops the
+// exporter. Without a configured exporter nothing is traced.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %v", err)
+	}
+
+	// Later sources override earlier ones, so the environment wins
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe trace resource: %v", err)
+	}
+
+	// The sampler and batching follow OTEL_TRACES_SAMPLER and OTEL_BSP_*
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// newExporter creates an OTLP exporter for the protocol in
+// OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL.
+// Endpoints, headers, TLS and timeouts are read from the environment by the exporter.
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	switch protocol {
+	case "", "http/protobuf":
+		return otlptracehttp.New(ctx)
+	case "grpc":
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q. Use http/protobuf or grpc", protocol)
+	}
+}
@@ -0,0 +1,264 @@
+package types
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Alert channel types
+const (
+	ChannelWebhook = "webhook" // POSTs the alert as JSON
+	ChannelSlack   = "slack"   // POSTs a Slack-compatible incoming webhook message
+	ChannelEmail   = "email"   // sends an email over SMTP
+)
+
+// DefaultAlertAfter is how many consecutive failed runs raise an alert by default
+const DefaultAlertAfter = 1
+
+// AlertChannel is a destination for alerts. A job's channel that only has a
+// name refers to the channel of that name in the configuration file.
+type AlertChannel struct {
+	Name string `json:",omitempty" yaml:"name,omitempty"`
+	Type string `json:",omitempty" yaml:"type,omitempty"` // ChannelWebhook, ChannelSlack or ChannelEmail
+
+	URL     string            `json:",omitempty" yaml:"url,omitempty"`     // webhook and Slack channels
+	Headers map[string]string `json:",omitempty" yaml:"headers,omitempty"` // webhook channels
+
+	SMTPServer string   `json:",omitempty" yaml:"smtp_server,omitempty"` // "host:port" of email channels
+	Username   string   `json:",omitempty" yaml:"username,omitempty"`    // SMTP login, empty to send without one
+	Password   string   `json:",omitempty" yaml:"password,omitempty"`
+	From       string   `json:",omitempty" yaml:"from,omitempty"`
+	To         []string `json:",omitempty" yaml:"to,omitempty"`
+}
+
+// IsReference reports whether the channel refers to a configured channel by name
+func (c AlertChannel) IsReference() bool {
+	return c.Type == "" && c.Name != ""
+}
+
+// String names the channel in logs and output
+func (c AlertChannel) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	switch c.Type {
+	case ChannelEmail:
+		return "email to " + strings.Join(c.To, ", ")
+	default:
+		return c.Type
+	}
+}
+
+// Validate checks that the channel can deliver alerts
+func (c AlertChannel) Validate() error {
+	if c.IsReference() {
+		return nil
+	}
+
+	switch c.Type {
+	case ChannelWebhook, ChannelSlack:
+		if strings.Contains(c.URL, "{{") {
+			// A URL kept in a secret is only known when an alert is sent
+			break
+		}
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s channel needs an http:// or https:// URL", c.Type)
+		}
+	case ChannelEmail:
+		if c.SMTPServer == "" || !strings.Contains(c.SMTPServer, ":") {
+			return fmt.Errorf("email channel needs an SMTP server like 'smtp.example.com:587'")
+		}
+		if _, err := mail.ParseAddress(c.From); err != nil {
+			return fmt.Errorf("invalid email sender %q", c.From)
+		}
+		if len(c.To) == 0 {
+			return fmt.Errorf("email channel needs at least one recipient")
+		}
+		for _, to := range c.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("invalid email recipient %q", to)
+			}
+		}
+	case "":
+		return fmt.Errorf("alert channel needs a type or the name of a configured channel")
+	default:
+		return fmt.Errorf("invalid alert channel type %q. Use webhook, slack or email", c.Type)
+	}
+	return nil
+}
+
+// AlertPolicy decides when alerts are sent and where to. The policy in the
+// configuration file applies to every job; a job's own policy overrides the
+// rules it sets and replaces the channels if it lists any.
+type AlertPolicy struct {
+	Channels []AlertChannel `json:",omitempty" yaml:"channels,omitempty"`
+	After    int            `json:",omitempty" yaml:"after,omitempty"`    // consecutive failed runs before alerting, zero means DefaultAlertAfter
+	Recovery *bool          `json:",omitempty" yaml:"recovery,omitempty"` // alert when a failing job succeeds again, nil means true
+	Repeat   Duration       `json:",omitempty" yaml:"repeat,omitempty"`   // remind about a job that keeps failing, zero means never
+	Disabled bool           `json:",omitempty" yaml:"disabled,omitempty"` // send no alerts for the job
+}
+
+// Threshold returns how many consecutive failed runs raise an alert
+func (p *AlertPolicy) Threshold() int {
+	if p == nil || p.After <= 0 {
+		return DefaultAlertAfter
+	}
+	return p.After
+}
+
+// AlertOnRecovery reports whether an alert is sent when a failing job recovers
+func (p *AlertPolicy) AlertOnRecovery() bool {
+	return p == nil || p.Recovery == nil || *p.Recovery
+}
+
+// Validate checks the rules and every channel
+func (p *AlertPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.After < 0 {
+		return fmt.Errorf("alert threshold cannot be negative")
+	}
+	if p.Repeat < 0 {
+		return fmt.Errorf("alert repeat interval cannot be negative")
+	}
+
+	names := make(map[string]bool)
+	for _, channel := range p.Channels {
+		if err := channel.Validate(); err != nil {
+			return err
+		}
+		if channel.Name != "" && !channel.IsReference() {
+			if names[channel.Name] {
+				return fmt.Errorf("duplicate alert channel %q", channel.Name)
+			}
+			names[channel.Name] = true
+		}
+	}
+	return nil
+}
+
+// WithDefaults returns the job's policy with unset rules taken from the
+// global one and channel references replaced by the channels they name.
+// Either may be nil. References to unknown channels are dropped and
+// reported in the error, next to the usable policy.
+func (p *AlertPolicy) WithDefaults(global *AlertPolicy) (AlertPolicy, error) {
+	var merged AlertPolicy
+	if global != nil {
+		merged = *global
+	}
+	if p == nil {
+		return merged, nil
+	}
+
+	if len(p.Channels) > 0 {
+		merged.Channels = nil
+	}
+	var unknown []string
+	for _, channel := range p.Channels {
+		if !channel.IsReference() {
+			merged.Channels = append(merged.Channels, channel)
+			continue
+		}
+		found := false
+		if global != nil {
+			for _, configured := range global.Channels {
+				if configured.Name == channel.Name {
+					merged.Channels = append(merged.Channels, configured)
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			unknown = append(unknown, channel.Name)
+		}
+	}
+
+	if p.After != 0 {
+		merged.After = p.After
+	}
+	if p.Recovery != nil {
+		merged.Recovery = p.Recovery
+	}
+	if p.Repeat != 0 {
+		merged.Repeat = p.Repeat
+	}
+	merged.Disabled = merged.Disabled || p.Disabled
+
+	if len(unknown) > 0 {
+		return merged, fmt.Errorf("unknown alert channel %s", strings.Join(unknown, ", "))
+	}
+	return merged, nil
+}
+
+// AlertKind tells what an alert is about
+type AlertKind string
+
+const (
+	AlertFailing      AlertKind = "failing"       // a job reached the failure threshold
+	AlertStillFailing AlertKind = "still_failing" // a reminder about a job that keeps failing
+	AlertRecovered    AlertKind = "recovered"     // a failing job succeeded again
+	AlertTest         AlertKind = "test"          // sent by 'tempo alert test'
+)
+
+// Alert is the message sent to alert channels. Webhook channels receive it as JSON.
+type Alert struct {
+	Kind         AlertKind  `json:"kind"`
+	JobID        string     `json:"job_id"`
+	URL          string     `json:"url,omitempty"` // the job's URL, redacted
+	Time         time.Time  `json:"time"`
+	Failures     int        `json:"failures"`               // consecutive failed runs
+	FailingSince time.Time  `json:"failing_since,omitzero"` // when the first of them finished
+	Execution    *Execution `json:"execution,omitempty"`    // the last attempt of the run that caused the alert
+}
+
+// Subject is a one-line title of the alert
+func (a Alert) Subject() string {
+	switch a.Kind {
+	case AlertFailing:
+		return fmt.Sprintf("Job %s is failing", a.JobID)
+	case AlertStillFailing:
+		return fmt.Sprintf("Job %s is still failing", a.JobID)
+	case AlertRecovered:
+		return fmt.Sprintf("Job %s recovered", a.JobID)
+	default:
+		return fmt.Sprintf("Test alert for job %s", a.JobID)
+	}
+}
+
+// Summary describes the alert in a few lines of plain text
+func (a Alert) Summary() string {
+	var b strings.Builder
+	b.WriteString(a.Subject())
+
+	since := a.FailingSince.Local().Format("2006-01-02 15:04:05")
+	switch a.Kind {
+	case AlertFailing, AlertStillFailing:
+		fmt.Fprintf(&b, "\n%d consecutive failed run(s) since %s", a.Failures, since)
+	case AlertRecovered:
+		fmt.Fprintf(&b, "\nSucceeded after %d failed run(s) since %s", a.Failures, since)
+	default:
+		b.WriteString("\nThis channel receives the job's alerts")
+	}
+	if a.URL != "" {
+		fmt.Fprintf(&b, "\nURL: %s", a.URL)
+	}
+
+	if exec := a.Execution; exec != nil && exec.Status != StatusSuccess {
+		switch {
+		case exec.Error != "":
+			fmt.Fprintf(&b, "\nLast error: %s", exec.Error)
+		case exec.StatusCode != 0:
+			fmt.Fprintf(&b, "\nLast status: %d", exec.StatusCode)
+		}
+		for _, failure := range exec.Failures {
+			fmt.Fprintf(&b, "\nAssertion failed: %s (expected %s, got %s)", failure.Check, failure.Expected, failure.Actual)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package types
+
+// Assertions are the checks a response must pass for an execution to
+// succeed. Without Status, any status code below 400 passes.
+type Assertions struct {
+	Status       []string          `json:",omitempty" yaml:"status,omitempty"`        // "200", "2xx" or "200-299"
+	MaxLatency   Duration          `json:",omitempty" yaml:"max_latency,omitempty"`   // zero means unbounded
+	Headers      map[string]string `json:",omitempty" yaml:"headers,omitempty"`       // header name to regular expression
+	BodyContains []string          `json:",omitempty" yaml:"body_contains,omitempty"` // substrings the body must contain
+	BodyMatches  []string          `json:",omitempty" yaml:"body_matches,omitempty"`  // regular expressions the body must match
+	JSON         []JSONAssertion   `json:",omitempty" yaml:"json,omitempty"`
+	JSONSchema   interface{}       `json:",omitempty" yaml:"json_schema,omitempty"` // inline schema or path to a schema file
+}
+
+// JSONAssertion checks the value at a JSONPath in a JSON response body.
+// Without Equals or Exists the value must exist.
+type JSONAssertion struct {
+	Path   string      `yaml:"path"`                               // e.g. "$.data.items[0].id"
+	Equals interface{} `json:",omitempty" yaml:"equals,omitempty"` // expected JSON value
+	Exists *bool       `json:",omitempty" yaml:"exists,omitempty"` // whether the path must (not) exist
+}
+
+// AssertionFailure describes a check a response did not pass
+type AssertionFailure struct {
+	Check    string `json:"check"` // e.g. "status", "header Content-Type", "json $.status"
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
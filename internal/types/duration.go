@@ -0,0 +1,27 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration that is written to job files as a
+// human readable string such as "500ms" or "2m"
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %v", text, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
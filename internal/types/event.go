@@ -0,0 +1,29 @@
+package types
+
+import "time"
+
+// EventType identifies what happened during a job execution
+type EventType string
+
+const (
+	EventStart   EventType = "start"
+	EventSuccess EventType = "success"
+	EventFailure EventType = "failure"
+	EventRetry   EventType = "retry"
+
+	EventSkipped   EventType = "skipped"   // a scheduled run did not start because of the job's overlap policy
+	EventCancelled EventType = "cancelled" // a run was cancelled by a newer run
+)
+
+// Event is a live notification published by a running scheduler
+type Event struct {
+	Type        EventType `json:"type"`
+	Time        time.Time `json:"time"`
+	JobID       string    `json:"job_id"`
+	ExecutionID string    `json:"execution_id,omitempty"`
+	Attempt     int       `json:"attempt,omitempty"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	LatencyMs   int64     `json:"latency_ms,omitempty"`
+	DelayMs     int64     `json:"delay_ms,omitempty"` // wait before the next attempt, for retry events
+	Error       string    `json:"error,omitempty"`
+}
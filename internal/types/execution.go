@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// ExecutionStatus is the outcome of a single webhook execution
+type ExecutionStatus string
+
+const (
+	StatusSuccess   ExecutionStatus = "success"
+	StatusFailure   ExecutionStatus = "failure"
+	StatusSkipped   ExecutionStatus = "skipped"   // not started because of the job's overlap policy
+	StatusCancelled ExecutionStatus = "cancelled" // stopped by a newer run under the cancel overlap policy
+)
+
+// Trigger values describe what caused an execution
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Execution is a history record of one webhook call made for a job
+type Execution struct {
+	ID           string             `json:"id"`
+	JobID        string             `json:"job_id"`
+	Trigger      string             `json:"trigger"`
+	RunID        string             `json:"run_id,omitempty"` // shared by the attempts of one run
+	Attempt      int                `json:"attempt"`
+	ScheduledAt  time.Time          `json:"scheduled_at"`
+	StartedAt    time.Time          `json:"started_at"`
+	FinishedAt   time.Time          `json:"finished_at"`
+	Status       ExecutionStatus    `json:"status"`
+	StatusCode   int                `json:"status_code,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	Failures     []AssertionFailure `json:"assertion_failures,omitempty"`
+	LatencyMs    int64              `json:"latency_ms"`
+	QueueMs      int64              `json:"queue_ms,omitempty"` // time spent waiting for a free worker
+	ResponseSize int64              `json:"response_size"`
+}
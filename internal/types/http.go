@@ -0,0 +1,151 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// HTTP versions a job can be restricted to with HTTPConfig.Version
+const (
+	HTTPVersion1 = "1.1"
+	HTTPVersion2 = "2"
+)
+
+// ProxyNone disables the proxy set in the environment
+const ProxyNone = "none"
+
+// Defaults of the HTTP client settings
+const (
+	DefaultHTTPTimeout  = 10 * time.Second
+	DefaultMaxRedirects = 10
+)
+
+// HTTPConfig holds the HTTP client settings of a job, or the defaults of all
+// jobs in the configuration file. Unset fields fall back to the defaults.
+type HTTPConfig struct {
+	Timeout            Duration `json:",omitempty" yaml:"timeout,omitempty"`              // whole request including the body, zero means DefaultHTTPTimeout
+	FollowRedirects    *bool    `json:",omitempty" yaml:"follow_redirects,omitempty"`     // nil means follow
+	MaxRedirects       int      `json:",omitempty" yaml:"max_redirects,omitempty"`        // zero means DefaultMaxRedirects
+	CACert             string   `json:",omitempty" yaml:"ca_cert,omitempty"`              // PEM bundle replacing the system roots
+	ClientCert         string   `json:",omitempty" yaml:"client_cert,omitempty"`          // PEM certificate for mutual TLS
+	ClientKey          string   `json:",omitempty" yaml:"client_key,omitempty"`           // PEM key of ClientCert, empty if the certificate file holds it
+	InsecureSkipVerify *bool    `json:",omitempty" yaml:"insecure_skip_verify,omitempty"` // accept any server certificate
+	Proxy              string   `json:",omitempty" yaml:"proxy,omitempty"`                // http, https or socks5 URL, ProxyNone, or empty for the environment
+	Version            string   `json:",omitempty" yaml:"version,omitempty"`              // HTTPVersion1, HTTPVersion2 or empty to negotiate
+}
+
+// WithDefaults returns the settings with unset fields taken from defaults.
+// Either may be nil.
+func (c *HTTPConfig) WithDefaults(defaults *HTTPConfig) *HTTPConfig {
+	if c == nil && defaults == nil {
+		return nil
+	}
+
+	var merged HTTPConfig
+	if defaults != nil {
+		merged = *defaults
+	}
+	if c == nil {
+		return &merged
+	}
+
+	if c.Timeout != 0 {
+		merged.Timeout = c.Timeout
+	}
+	if c.FollowRedirects != nil {
+		merged.FollowRedirects = c.FollowRedirects
+	}
+	if c.MaxRedirects != 0 {
+		merged.MaxRedirects = c.MaxRedirects
+	}
+	if c.CACert != "" {
+		merged.CACert = c.CACert
+	}
+	if c.ClientCert != "" {
+		// A key from the defaults belongs to the default certificate
+		merged.ClientCert = c.ClientCert
+		merged.ClientKey = c.ClientKey
+	}
+	if c.InsecureSkipVerify != nil {
+		merged.InsecureSkipVerify = c.InsecureSkipVerify
+	}
+	if c.Proxy != "" {
+		merged.Proxy = c.Proxy
+	}
+	if c.Version != "" {
+		merged.Version = c.Version
+	}
+	return &merged
+}
+
+// RequestTimeout returns the timeout of a request
+func (c *HTTPConfig) RequestTimeout() time.Duration {
+	if c == nil || c.Timeout == 0 {
+		return DefaultHTTPTimeout
+	}
+	return time.Duration(c.Timeout)
+}
+
+// Redirects returns how many redirects are followed, zero if none are
+func (c *HTTPConfig) Redirects() int {
+	switch {
+	case c == nil:
+		return DefaultMaxRedirects
+	case c.FollowRedirects != nil && !*c.FollowRedirects:
+		return 0
+	case c.MaxRedirects == 0:
+		return DefaultMaxRedirects
+	default:
+		return c.MaxRedirects
+	}
+}
+
+// SkipVerify reports whether server certificates are accepted without verification
+func (c *HTTPConfig) SkipVerify() bool {
+	return c != nil && c.InsecureSkipVerify != nil && *c.InsecureSkipVerify
+}
+
+// IsZero reports whether no setting is made
+func (c *HTTPConfig) IsZero() bool {
+	return c == nil || *c == HTTPConfig{}
+}
+
+// Validate checks that the settings are usable. Certificate files are only
+// read when a client is created.
+func (c *HTTPConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if c.MaxRedirects < 0 {
+		return fmt.Errorf("max redirects cannot be negative")
+	}
+	if c.ClientKey != "" && c.ClientCert == "" {
+		return fmt.Errorf("a client key requires a client certificate")
+	}
+
+	switch c.Version {
+	case "", HTTPVersion1, HTTPVersion2:
+	default:
+		return fmt.Errorf("invalid HTTP version %q. Use '1.1' or '2'", c.Version)
+	}
+
+	if c.Proxy != "" && c.Proxy != ProxyNone {
+		u, err := url.Parse(c.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("invalid proxy %q. Use an http://, https:// or socks5:// URL, or 'none'", c.Proxy)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid proxy %q: missing host", c.Proxy)
+		}
+	}
+	return nil
+}
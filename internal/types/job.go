@@ -1,11 +1,133 @@
 package types
 
+import (
+	"fmt"
+	"time"
+)
+
+// Schedule kinds. A job sets exactly one of CronExpr, At and Every.
+const (
+	ScheduleCron  = "cron"  // fires whenever the cron expression matches
+	ScheduleAt    = "at"    // fires once at a point in time
+	ScheduleEvery = "every" // fires at fixed intervals from an anchor time
+)
+
+// Overlap policies decide what happens when a job is due while its previous
+// scheduled run is still in progress
+const (
+	OverlapAllow  = "allow"  // run both at the same time
+	OverlapSkip   = "skip"   // skip the new run
+	OverlapQueue  = "queue"  // start the new run when the previous one ends
+	OverlapCancel = "cancel" // cancel the previous run and start the new one
+)
+
+// DefaultQueueLimit is how many runs may wait under the queue overlap policy by default
+const DefaultQueueLimit = 1
+
 type Job struct {
-	ID       string
-	URL      string
-	CronExpr string // "*/10 * * * * *"
+	ID       string `yaml:"id"`
+	URL      string `yaml:"url"`
+	CronExpr string `yaml:"schedule,omitempty"`                   // "*/10 * * * * *"
+	TimeZone string `json:",omitempty" yaml:"timezone,omitempty"` // IANA name like "Europe/Berlin", empty means the scheduler's local time zone
+
+	At          *time.Time `json:",omitempty" yaml:"at,omitempty"`           // one-shot run time
+	Every       Duration   `json:",omitempty" yaml:"every,omitempty"`        // interval between runs
+	Anchor      *time.Time `json:",omitempty" yaml:"anchor,omitempty"`       // runs happen at Anchor + n*Every
+	CompletedAt *time.Time `json:",omitempty" yaml:"completed_at,omitempty"` // when a one-shot job fired; it is not scheduled again
+
+	Method  string            `yaml:"method"`            // "GET", "POST", "PUT", "DELETE"
+	Body    string            `yaml:"body,omitempty"`    // "{\"key\": \"value\"}"
+	Headers map[string]string `yaml:"headers,omitempty"` // "{\"Content-Type\": \"application/json\"}"
+
+	Tags []string `json:",omitempty" yaml:"tags,omitempty"` // for selecting jobs in bulk, e.g. with 'tempo pause --tag'
+
+	Paused      bool       `json:",omitempty" yaml:"paused,omitempty"`       // scheduled runs are skipped while paused
+	PausedUntil *time.Time `json:",omitempty" yaml:"paused_until,omitempty"` // automatic resume time, nil means until resumed
+
+	Overlap    string `json:",omitempty" yaml:"overlap,omitempty"`     // OverlapAllow, OverlapSkip, OverlapQueue or OverlapCancel; empty means allow
+	QueueLimit int    `json:",omitempty" yaml:"queue_limit,omitempty"` // runs that may wait under OverlapQueue, zero means DefaultQueueLimit
+
+	HTTP *HTTPConfig `json:",omitempty" yaml:"http,omitempty"` // nil uses the global defaults
+
+	Retry  *RetryPolicy `json:",omitempty" yaml:"retry,omitempty"`  // nil means a single attempt
+	Assert *Assertions  `json:",omitempty" yaml:"assert,omitempty"` // nil means any status below 400 passes
+
+	Alert *AlertPolicy `json:",omitempty" yaml:"alert,omitempty"` // nil uses the global alert policy
+}
+
+// ScheduleKind returns which kind of schedule the job uses
+func (j Job) ScheduleKind() string {
+	switch {
+	case j.At != nil:
+		return ScheduleAt
+	case j.Every != 0:
+		return ScheduleEvery
+	default:
+		return ScheduleCron
+	}
+}
+
+// Schedule describes the job's schedule for display
+func (j Job) Schedule() string {
+	switch j.ScheduleKind() {
+	case ScheduleAt:
+		return "at " + j.At.Format(time.RFC3339)
+	case ScheduleEvery:
+		if j.Anchor != nil {
+			return "every " + j.Every.String() + " from " + j.Anchor.Format(time.RFC3339)
+		}
+		return "every " + j.Every.String()
+	default:
+		if j.TimeZone != "" {
+			return j.CronExpr + " (" + j.TimeZone + ")"
+		}
+		return j.CronExpr
+	}
+}
+
+// OverlapPolicy returns the job's overlap policy, defaulting to OverlapAllow
+func (j Job) OverlapPolicy() string {
+	if j.Overlap == "" {
+		return OverlapAllow
+	}
+	return j.Overlap
+}
+
+// MaxQueued returns how many runs may wait under the queue overlap policy
+func (j Job) MaxQueued() int {
+	if j.QueueLimit <= 0 {
+		return DefaultQueueLimit
+	}
+	return j.QueueLimit
+}
+
+// ValidateOverlap checks the overlap policy settings
+func (j Job) ValidateOverlap() error {
+	switch j.OverlapPolicy() {
+	case OverlapAllow, OverlapSkip, OverlapQueue, OverlapCancel:
+	default:
+		return fmt.Errorf("invalid overlap policy %q. Use allow, skip, queue or cancel", j.Overlap)
+	}
+	if j.QueueLimit < 0 {
+		return fmt.Errorf("queue limit cannot be negative")
+	}
+	if j.QueueLimit > 0 && j.OverlapPolicy() != OverlapQueue {
+		return fmt.Errorf("a queue limit requires the queue overlap policy")
+	}
+	return nil
+}
+
+// IsPaused reports whether scheduled runs of the job are skipped at the given time
+func (j Job) IsPaused(now time.Time) bool {
+	return j.Paused && (j.PausedUntil == nil || now.Before(*j.PausedUntil))
+}
 
-	Method  string            // "GET", "POST", "PUT", "DELETE"
-	Body    string            // "{\"key\": \"value\"}"
-	Headers map[string]string // "{\"Content-Type\": \"application/json\"}"
+// HasTag reports whether the job carries the tag
+func (j Job) HasTag(tag string) bool {
+	for _, t := range j.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobKeysMatchFieldNames(t *testing.T) {
+	job := Job{
+		ID:    "a",
+		HTTP:  &HTTPConfig{Timeout: Duration(5 * time.Second), CACert: "/ca.pem"},
+		Retry: &RetryPolicy{MaxAttempts: 3},
+		Alert: &AlertPolicy{Channels: []AlertChannel{{Type: ChannelEmail, SMTPServer: "smtp.example.com:587"}}, After: 2},
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"HTTP":{"Timeout":"5s","CACert":"/ca.pem"}`, `"MaxAttempts":3`, `"SMTPServer":"smtp.example.com:587"`, `"After":2`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("job JSON lacks %s: %s", key, data)
+		}
+	}
+}
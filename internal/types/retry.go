@@ -0,0 +1,99 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Retry conditions that can be listed in RetryPolicy.RetryOn besides
+// exact status codes ("502") and status classes ("5xx")
+const (
+	RetryOnNetwork = "network" // connection errors, DNS failures, resets
+	RetryOnTimeout = "timeout" // the request timed out
+)
+
+// DefaultRetryOn is used when a policy does not list any conditions
+var DefaultRetryOn = []string{RetryOnNetwork, RetryOnTimeout, "429", "5xx"}
+
+// RetryPolicy controls how failed executions of a job are retried
+type RetryPolicy struct {
+	MaxAttempts  int      `yaml:"max_attempts"`                         // total attempts including the first one
+	InitialDelay Duration `yaml:"initial_delay"`                        // delay before the first retry
+	Multiplier   float64  `yaml:"multiplier"`                           // growth factor applied to the delay after every retry
+	MaxDelay     Duration `yaml:"max_delay"`                            // upper bound for a single delay, zero means unbounded
+	Jitter       float64  `yaml:"jitter"`                               // fraction (0-1) of each delay that is randomized
+	RetryOn      []string `json:",omitempty" yaml:"retry_on,omitempty"` // "network", "timeout", "5xx", "502", ...
+}
+
+// Validate checks that the policy values are usable
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry max attempts must be at least 1")
+	}
+	if p.InitialDelay < 0 || p.MaxDelay < 0 {
+		return fmt.Errorf("retry delays cannot be negative")
+	}
+	if p.Multiplier != 0 && p.Multiplier < 1 {
+		return fmt.Errorf("retry multiplier must be at least 1")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+	for _, condition := range p.RetryOn {
+		if !validRetryCondition(condition) {
+			return fmt.Errorf("invalid retry condition: %s. Use 'network', 'timeout', a status code like '502' or a class like '5xx'", condition)
+		}
+	}
+	return nil
+}
+
+// Conditions returns the configured retry conditions or the defaults
+func (p *RetryPolicy) Conditions() []string {
+	if len(p.RetryOn) == 0 {
+		return DefaultRetryOn
+	}
+	return p.RetryOn
+}
+
+// MatchesStatus reports whether a response status code is retryable under the policy
+func (p *RetryPolicy) MatchesStatus(statusCode int) bool {
+	code := strconv.Itoa(statusCode)
+	for _, condition := range p.Conditions() {
+		condition = strings.ToLower(condition)
+		if condition == code {
+			return true
+		}
+		if len(condition) == 3 && strings.HasSuffix(condition, "xx") && condition[0] == code[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the named condition ("network", "timeout") is retryable
+func (p *RetryPolicy) Matches(condition string) bool {
+	for _, c := range p.Conditions() {
+		if strings.EqualFold(c, condition) {
+			return true
+		}
+	}
+	return false
+}
+
+func validRetryCondition(condition string) bool {
+	condition = strings.ToLower(condition)
+	switch condition {
+	case RetryOnNetwork, RetryOnTimeout:
+		return true
+	}
+
+	if len(condition) != 3 {
+		return false
+	}
+	if strings.HasSuffix(condition, "xx") {
+		return condition[0] >= '1' && condition[0] <= '5'
+	}
+	code, err := strconv.Atoi(condition)
+	return err == nil && code >= 100 && code <= 599
+}
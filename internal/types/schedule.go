@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// ScheduledJob describes a job loaded in a running scheduler
+type ScheduledJob struct {
+	Job     Job       `json:"job"`
+	NextRun time.Time `json:"next_run"`
+	PrevRun time.Time `json:"prev_run"`
+	Paused  bool      `json:"paused"`
+}
+
+// ReloadSummary lists the job IDs changed by reloading a running scheduler
+type ReloadSummary struct {
+	Added   []string `json:"added,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// PoolStats describes the load of a running scheduler's execution workers
+type PoolStats struct {
+	Workers   int   `json:"workers"`
+	Busy      int   `json:"busy"`       // workers running an execution
+	Queued    int   `json:"queued"`     // executions waiting for a worker
+	QueueSize int   `json:"queue_size"` // executions that may wait before new ones are skipped
+	Completed int64 `json:"completed"`
+	Rejected  int64 `json:"rejected"`    // executions skipped because the queue was full
+	WaitAvgMs int64 `json:"wait_avg_ms"` // average time executions waited for a worker
+	WaitMaxMs int64 `json:"wait_max_ms"`
+}
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"tempo/internal/config"
+)
+
+// TokenFileName holds the generated API token in the data directory
+const TokenFileName = "dashboard.token"
+
+// Access controls who may use the dashboard
+type Access struct {
+	Token  string   // bearer token every API request must carry
+	Hosts  []string // host names accepted besides localhost and IP addresses
+	Remote bool     // allow binding addresses other than loopback ones
+}
+
+// LoadToken returns the generated API token of the data directory, creating
+// it on first use. Only the user may read the file.
+func LoadToken(dataDir string) (string, error) {
+	path := filepath.Join(dataDir, TokenFileName)
+	data, err := os.ReadFile(path)
+	if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read dashboard token: %v", err)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate dashboard token: %v", err)
+	}
+	token := hex.EncodeToString(random)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to save dashboard token: %v", err)
+	}
+	return token, nil
+}
+
+// ListenAddr completes a dashboard address: a port alone, such as "8080" or
+// ":8080", is bound on 127.0.0.1
+func ListenAddr(addr string) string {
+	if !strings.Contains(addr, ":") {
+		addr = ":" + addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// Check returns an error if the dashboard may not be served on the address
+func (a Access) Check(addr string) error {
+	host, _, err := net.SplitHostPort(ListenAddr(addr))
+	if err != nil {
+		return fmt.Errorf("invalid dashboard address %q: %v", addr, err)
+	}
+	if !a.Remote && !isLoopback(host) {
+		return fmt.Errorf("refusing to serve the dashboard on %s without a configured token. Bind it to 127.0.0.1, or set dashboard.token in %s", addr, config.FileName)
+	}
+	return nil
+}
+
+// isLoopback reports whether a host only accepts connections from this machine
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// allowedHost reports whether a request's Host header names the dashboard.
+// Pages that rebind their own domain name to this machine send that name,
+// so only IP addresses, localhost and the configured names are accepted.
+func (s *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if host == "" {
+		return false
+	}
+	if net.ParseIP(host) != nil || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	return slices.ContainsFunc(s.access.Hosts, func(allowed string) bool {
+		return strings.EqualFold(allowed, host)
+	})
+}
+
+// authorized reports whether the request carries the API token
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.access.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.access.Token)) == 1
+}
+
+// protect guards the dashboard against other sites and unauthorized clients:
+// every request must name an allowed host, API requests need the token, and
+// changes must be JSON from the dashboard's own origin
+func (s *Server) protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("unknown host %q. Add it to dashboard.hosts in %s to allow it", r.Host, config.FileName))
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/") && !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid dashboard token. Open the dashboard with the link printed by 'tempo start'"))
+			return
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if origin := r.Header.Get("Origin"); origin != "" {
+				if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+					writeError(w, http.StatusForbidden, fmt.Errorf("cross-origin requests are not allowed"))
+					return
+				}
+			}
+			// A chunked body has no length, so only an empty one is exempt
+			if r.ContentLength != 0 && !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("requests must be JSON"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
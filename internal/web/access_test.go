@@ -0,0 +1,196 @@
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"testing"
+)
+
+const testToken = "0123456789abcdef0123"
+
+type fakeStore struct {
+	jobs map[string]types.Job
+}
+
+func (s *fakeStore) GetAllJobs() []types.Job {
+	var jobs []types.Job
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func (s *fakeStore) UpdateJob(id string, change func(job types.Job, exists bool) (types.Job, error)) error {
+	current, exists := s.jobs[id]
+	job, err := change(current, exists)
+	if errors.Is(err, storage.ErrJobUnchanged) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *fakeStore) QueryExecutions(filter storage.HistoryFilter) ([]types.Execution, error) {
+	return nil, nil
+}
+
+type fakeScheduler struct{}
+
+func (fakeScheduler) Jobs() []types.ScheduledJob { return nil }
+
+func (fakeScheduler) Trigger(jobID string) (types.Execution, error) {
+	return types.Execution{ID: "exec", JobID: jobID, Status: types.StatusSuccess}, nil
+}
+
+func (fakeScheduler) Reload() (types.ReloadSummary, error) { return types.ReloadSummary{}, nil }
+
+func newTestServer(access Access) (*Server, *fakeStore) {
+	store := &fakeStore{jobs: map[string]types.Job{
+		"a": {ID: "a", Method: "GET", URL: "https://example.com/", CronExpr: "0 * * * * *"},
+	}}
+	validate := func(types.Job) error { return nil }
+	return New(store, fakeScheduler{}, validate, nil, access), store
+}
+
+func TestProtect(t *testing.T) {
+	s, store := newTestServer(Access{Token: testToken, Hosts: []string{"tempo.example.com"}})
+	handler := s.Handler()
+	bearer := "Bearer " + testToken
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		host    string
+		headers map[string]string
+		body    string
+		chunked bool
+		want    int
+	}{
+		{name: "page without token", method: "GET", path: "/", want: http.StatusOK},
+		{name: "api without token", method: "GET", path: "/api/jobs", want: http.StatusUnauthorized},
+		{name: "api with wrong token", method: "GET", path: "/api/jobs", headers: map[string]string{"Authorization": "Bearer nope"}, want: http.StatusUnauthorized},
+		{name: "api with token", method: "GET", path: "/api/jobs", headers: map[string]string{"Authorization": bearer}, want: http.StatusOK},
+		{name: "trigger without token", method: "POST", path: "/api/jobs/a/trigger", want: http.StatusUnauthorized},
+		{name: "trigger with token", method: "POST", path: "/api/jobs/a/trigger", headers: map[string]string{"Authorization": bearer}, want: http.StatusOK},
+
+		{name: "ipv6 host", method: "GET", path: "/api/jobs", host: "[::1]:8080", headers: map[string]string{"Authorization": bearer}, want: http.StatusOK},
+		{name: "localhost", method: "GET", path: "/api/jobs", host: "localhost:8080", headers: map[string]string{"Authorization": bearer}, want: http.StatusOK},
+		{name: "configured host", method: "GET", path: "/api/jobs", host: "Tempo.Example.com", headers: map[string]string{"Authorization": bearer}, want: http.StatusOK},
+		{name: "rebound domain", method: "GET", path: "/", host: "attacker.example:8080", want: http.StatusForbidden},
+		{name: "rebound domain with token", method: "GET", path: "/api/jobs", host: "attacker.example", headers: map[string]string{"Authorization": bearer}, want: http.StatusForbidden},
+
+		{name: "cross origin", method: "POST", path: "/api/jobs/a/pause", headers: map[string]string{"Authorization": bearer, "Origin": "https://attacker.example"}, want: http.StatusForbidden},
+		{name: "same origin", method: "POST", path: "/api/jobs/a/pause", headers: map[string]string{"Authorization": bearer, "Origin": "http://127.0.0.1:8080"}, want: http.StatusNoContent},
+		{name: "form body", method: "POST", path: "/api/jobs", headers: map[string]string{"Authorization": bearer, "Content-Type": "text/plain"}, body: `{"id": "b"}`, want: http.StatusUnsupportedMediaType},
+		{name: "chunked form body", method: "POST", path: "/api/jobs", headers: map[string]string{"Authorization": bearer, "Content-Type": "text/plain"}, body: `{"id": "b"}`, chunked: true, want: http.StatusUnsupportedMediaType},
+		{name: "chunked body without type", method: "PUT", path: "/api/jobs/a", headers: map[string]string{"Authorization": bearer}, body: `{"id": "a"}`, chunked: true, want: http.StatusUnsupportedMediaType},
+		{name: "json body", method: "POST", path: "/api/jobs", headers: map[string]string{"Authorization": bearer, "Content-Type": "application/json"}, body: `{"id": "b", "url": "https://example.com/", "cron_expr": "0 * * * * *"}`, want: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			req.Host = "127.0.0.1:8080"
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.chunked {
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, rec.Code, strings.TrimSpace(rec.Body.String()), tt.want)
+			}
+		})
+	}
+
+	if _, ok := store.jobs["b"]; !ok {
+		t.Error("the authorized JSON request did not create the job")
+	}
+	if !store.jobs["a"].Paused {
+		t.Error("the same-origin request did not pause the job")
+	}
+}
+
+func TestServeRefusesRemoteWithoutToken(t *testing.T) {
+	s, _ := newTestServer(Access{Token: testToken})
+	for _, addr := range []string{"0.0.0.0:0", ":::0", "192.0.2.1:0", "example.com:0"} {
+		if server, err := s.Serve(addr); err == nil {
+			server.Close()
+			t.Errorf("Serve(%q) succeeded without a configured token", addr)
+		}
+	}
+
+	server, err := s.Serve("0")
+	if err != nil {
+		t.Fatalf("Serve(%q) failed: %v", "0", err)
+	}
+	server.Close()
+
+	if err := (Access{Token: testToken, Remote: true}).Check("0.0.0.0:8080"); err != nil {
+		t.Errorf("Check() with a configured token = %v", err)
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	tests := map[string]string{
+		"8080":           "127.0.0.1:8080",
+		":8080":          "127.0.0.1:8080",
+		"0.0.0.0:8080":   "0.0.0.0:8080",
+		"[::1]:8080":     "[::1]:8080",
+		"localhost:8080": "localhost:8080",
+	}
+	for addr, want := range tests {
+		if got := ListenAddr(addr); got != want {
+			t.Errorf("ListenAddr(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestLoadToken(t *testing.T) {
+	dir := t.TempDir()
+	token, err := LoadToken(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token) != 64 {
+		t.Errorf("generated token %q, want 64 hex characters", token)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, TokenFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("token file mode = %v, want 0600", perm)
+	}
+
+	again, err := LoadToken(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != token {
+		t.Errorf("LoadToken() = %q on the second call, want the saved %q", again, token)
+	}
+}
@@ -0,0 +1,379 @@
+"use strict";
+
+// How often the job list is refreshed
+const REFRESH_INTERVAL = 5000;
+
+const jobsBody = document.querySelector("#jobs tbody");
+const rowTemplate = document.getElementById("job-row");
+const editor = document.getElementById("editor");
+const form = document.getElementById("job-form");
+const notice = document.getElementById("notice");
+
+// The link printed by 'tempo start' passes the API token in the fragment.
+// It is kept for later visits and removed from the address bar.
+const TOKEN_KEY = "tempo-dashboard-token";
+const linkToken = new URLSearchParams(location.hash.slice(1)).get("token");
+if (linkToken) {
+  localStorage.setItem(TOKEN_KEY, linkToken);
+  history.replaceState(null, "", location.pathname + location.search);
+}
+
+let jobs = [];
+let editing = null; // the job being edited, null when creating one
+let historyJob = null; // the job whose history is shown
+
+// api calls the dashboard API and returns the decoded response, throwing its error
+async function api(method, path, body) {
+  const options = { method, headers: { Authorization: "Bearer " + (localStorage.getItem(TOKEN_KEY) || "") } };
+  if (body !== undefined) {
+    options.headers["Content-Type"] = "application/json";
+    options.body = JSON.stringify(body);
+  }
+  const resp = await fetch(path, options);
+  if (resp.status === 204) {
+    return null;
+  }
+  const data = await resp.json().catch(() => ({ error: resp.statusText }));
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+function jobPath(id, action) {
+  return "api/jobs/" + encodeURIComponent(id) + (action ? "/" + action : "");
+}
+
+function showNotice(message, isError) {
+  notice.textContent = message;
+  notice.classList.toggle("error", !!isError);
+  notice.hidden = false;
+}
+
+function formatTime(value) {
+  if (!value) {
+    return "";
+  }
+  return new Date(value).toLocaleString();
+}
+
+// relativeTime describes a time as "in 5m" or "3h ago"
+function relativeTime(value) {
+  if (!value) {
+    return "—";
+  }
+  let seconds = Math.round((new Date(value) - Date.now()) / 1000);
+  const future = seconds >= 0;
+  seconds = Math.abs(seconds);
+
+  let text;
+  if (seconds < 60) {
+    text = seconds + "s";
+  } else if (seconds < 3600) {
+    text = Math.floor(seconds / 60) + "m";
+  } else if (seconds < 86400) {
+    text = Math.floor(seconds / 3600) + "h";
+  } else {
+    text = Math.floor(seconds / 86400) + "d";
+  }
+  return future ? "in " + text : text + " ago";
+}
+
+function formatSize(bytes) {
+  if (bytes < 1024) {
+    return bytes + " B";
+  }
+  if (bytes < 1024 * 1024) {
+    return (bytes / 1024).toFixed(1) + " KB";
+  }
+  return (bytes / 1024 / 1024).toFixed(1) + " MB";
+}
+
+async function loadJobs() {
+  try {
+    jobs = await api("GET", "api/jobs");
+  } catch (err) {
+    showNotice("Failed to load jobs: " + err.message, true);
+    return;
+  }
+  renderJobs();
+  document.getElementById("updated").textContent = "Updated " + new Date().toLocaleTimeString();
+}
+
+function renderJobs() {
+  jobsBody.replaceChildren();
+  document.getElementById("empty").hidden = jobs.length > 0;
+
+  for (const job of jobs) {
+    const row = rowTemplate.content.firstElementChild.cloneNode(true);
+    row.dataset.id = job.id;
+    row.classList.toggle("selected", job.id === historyJob);
+
+    row.querySelector(".id").textContent = job.id;
+    for (const tag of job.tags || []) {
+      const span = document.createElement("span");
+      span.textContent = tag;
+      row.querySelector(".tags").append(span);
+    }
+    row.querySelector(".method").textContent = job.method;
+    row.querySelector(".url").textContent = job.url;
+    row.querySelector(".schedule").textContent = job.schedule;
+
+    const state = row.querySelector(".state");
+    state.textContent = job.state.replace("_", " ");
+    state.classList.add("state-" + job.state);
+    if (job.paused_until) {
+      state.title = "Until " + formatTime(job.paused_until);
+      state.textContent += " until " + formatTime(job.paused_until);
+    } else if (job.state === "not_loaded") {
+      state.title = "The scheduler could not load this job, see its logs";
+    }
+
+    const next = row.querySelector(".next");
+    next.textContent = relativeTime(job.next_run);
+    next.title = formatTime(job.next_run);
+    const prev = row.querySelector(".prev");
+    prev.textContent = relativeTime(job.prev_run);
+    prev.title = formatTime(job.prev_run);
+
+    const paused = job.state === "paused";
+    row.querySelector('[data-action="pause"]').hidden = paused || job.state === "completed";
+    row.querySelector('[data-action="resume"]').hidden = !paused;
+    row.querySelector('[data-action="trigger"]').disabled = job.state === "not_loaded";
+
+    jobsBody.append(row);
+  }
+}
+
+jobsBody.addEventListener("click", async (event) => {
+  const button = event.target.closest("button");
+  if (!button) {
+    return;
+  }
+  const id = button.closest("tr").dataset.id;
+  const job = jobs.find((j) => j.id === id);
+
+  switch (button.dataset.action) {
+    case "trigger":
+      button.disabled = true;
+      button.textContent = "Running…";
+      try {
+        const exec = await api("POST", jobPath(id, "trigger"));
+        const failed = exec.status !== "success";
+        showNotice(`Ran ${id}: ${exec.status}` + (exec.status_code ? ` (${exec.status_code})` : "") +
+          (exec.error ? ": " + exec.error : ""), failed);
+      } catch (err) {
+        showNotice(`Failed to run ${id}: ${err.message}`, true);
+      }
+      button.disabled = false;
+      button.textContent = "Run";
+      if (historyJob === id) {
+        loadHistory(id);
+      }
+      break;
+    case "pause":
+    case "resume":
+      try {
+        await api("POST", jobPath(id, button.dataset.action));
+        showNotice(button.dataset.action === "pause" ? `Paused ${id}` : `Resumed ${id}`);
+      } catch (err) {
+        showNotice(`Failed to ${button.dataset.action} ${id}: ${err.message}`, true);
+      }
+      break;
+    case "edit":
+      openEditor(job);
+      return;
+    case "history":
+      loadHistory(id);
+      break;
+  }
+  loadJobs();
+});
+
+async function loadHistory(id) {
+  historyJob = id;
+  document.getElementById("history-job").textContent = id;
+  document.getElementById("history").hidden = false;
+  for (const row of jobsBody.rows) {
+    row.classList.toggle("selected", row.dataset.id === id);
+  }
+
+  let execs;
+  try {
+    execs = await api("GET", jobPath(id, "executions"));
+  } catch (err) {
+    showNotice(`Failed to load the history of ${id}: ${err.message}`, true);
+    return;
+  }
+
+  const body = document.querySelector("#history tbody");
+  body.replaceChildren();
+  document.getElementById("history-empty").hidden = execs.length > 0;
+  for (const exec of execs) {
+    const row = body.insertRow();
+    const cell = (text, className) => {
+      const td = row.insertCell();
+      td.textContent = text;
+      if (className) {
+        td.className = className;
+      }
+      return td;
+    };
+
+    cell(formatTime(exec.started_at)).title = "Scheduled for " + formatTime(exec.scheduled_at);
+    cell(exec.trigger);
+    cell(exec.attempt);
+    cell(exec.status, "status status-" + exec.status);
+    cell(exec.status_code || "—");
+    cell(exec.latency_ms + " ms");
+    cell(formatSize(exec.response_size));
+    cell(exec.queue_ms ? exec.queue_ms + " ms" : "—");
+
+    const details = [];
+    if (exec.error) {
+      details.push(exec.error);
+    }
+    for (const failure of exec.assertion_failures || []) {
+      details.push(`${failure.check}: expected ${failure.expected}, got ${failure.actual}`);
+    }
+    cell(details.join("\n"), "details");
+  }
+}
+
+// Editing
+
+const scheduleInput = form.elements.cron_expr;
+const timezoneInput = form.elements.timezone;
+const scheduleCheck = document.getElementById("schedule-check");
+const formError = document.getElementById("form-error");
+let checkTimer = null;
+
+function openEditor(job) {
+  editing = job || null;
+  form.reset();
+  formError.hidden = true;
+  document.getElementById("editor-title").textContent = job ? "Edit " + job.id : "New job";
+
+  form.elements.id.value = job ? job.id : "";
+  form.elements.id.readOnly = !!job;
+  if (job) {
+    form.elements.method.value = job.method;
+    form.elements.url.value = job.url;
+    form.elements.timezone.value = job.timezone || "";
+    form.elements.headers.value = Object.entries(job.headers || {})
+      .map(([name, value]) => name + ": " + value).join("\n");
+    form.elements.body.value = job.body || "";
+    form.elements.tags.value = (job.tags || []).join(", ");
+  }
+
+  // One-shot and interval schedules are changed with the CLI
+  const cron = !job || !!job.cron_expr;
+  scheduleInput.value = job ? job.cron_expr || job.schedule : "";
+  scheduleInput.disabled = !cron;
+  scheduleInput.required = cron;
+  scheduleCheck.className = "muted";
+  scheduleCheck.textContent = cron ? "" : "Change this schedule with 'tempo update'.";
+  if (cron && job) {
+    checkSchedule();
+  }
+
+  editor.showModal();
+}
+
+async function checkSchedule() {
+  if (scheduleInput.disabled) {
+    return true;
+  }
+  const params = new URLSearchParams({ expr: scheduleInput.value, timezone: timezoneInput.value });
+  let check;
+  try {
+    check = await api("GET", "api/schedule?" + params);
+  } catch (err) {
+    scheduleCheck.textContent = err.message;
+    return false;
+  }
+
+  scheduleCheck.classList.toggle("error", !!check.error);
+  scheduleCheck.classList.toggle("muted", !check.error);
+  if (check.error) {
+    scheduleCheck.textContent = check.error;
+    return false;
+  }
+  scheduleCheck.textContent = check.next && check.next.length
+    ? "Next runs: " + check.next.slice(0, 3).map(formatTime).join(", ")
+    : "This schedule never fires.";
+  return true;
+}
+
+for (const input of [scheduleInput, timezoneInput]) {
+  input.addEventListener("input", () => {
+    clearTimeout(checkTimer);
+    checkTimer = setTimeout(checkSchedule, 300);
+  });
+}
+
+// parseHeaders reads "Name: value" lines
+function parseHeaders(text) {
+  const headers = {};
+  for (const line of text.split("\n")) {
+    if (!line.trim()) {
+      continue;
+    }
+    const index = line.indexOf(":");
+    if (index <= 0) {
+      throw new Error(`invalid header "${line}". Use 'Name: value'`);
+    }
+    headers[line.slice(0, index).trim()] = line.slice(index + 1).trim();
+  }
+  return headers;
+}
+
+form.addEventListener("submit", async (event) => {
+  if (event.submitter && event.submitter.value === "cancel") {
+    return;
+  }
+  event.preventDefault();
+  formError.hidden = true;
+
+  let payload;
+  try {
+    payload = {
+      id: form.elements.id.value.trim(),
+      method: form.elements.method.value,
+      url: form.elements.url.value.trim(),
+      cron_expr: scheduleInput.disabled ? "" : scheduleInput.value.trim(),
+      timezone: timezoneInput.value.trim(),
+      headers: parseHeaders(form.elements.headers.value),
+      body: form.elements.body.value,
+      tags: form.elements.tags.value.split(",").map((t) => t.trim()).filter(Boolean),
+    };
+  } catch (err) {
+    formError.textContent = err.message;
+    formError.hidden = false;
+    return;
+  }
+  if (!(await checkSchedule())) {
+    return;
+  }
+
+  try {
+    if (editing) {
+      await api("PUT", jobPath(editing.id), payload);
+      showNotice(`Saved ${payload.id}`);
+    } else {
+      await api("POST", "api/jobs", payload);
+      showNotice(`Created ${payload.id}`);
+    }
+  } catch (err) {
+    formError.textContent = err.message;
+    formError.hidden = false;
+    return;
+  }
+  editor.close();
+  loadJobs();
+});
+
+document.getElementById("new-job").addEventListener("click", () => openEditor(null));
+
+loadJobs();
+setInterval(loadJobs, REFRESH_INTERVAL);
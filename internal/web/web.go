@@ -0,0 +1,433 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"tempo/internal/logging"
+	"tempo/internal/redact"
+	"tempo/internal/schedule"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"time"
+)
+
+//go:embed static
+var static embed.FS
+
+// Job states shown in the dashboard
+const (
+	StateActive    = "active"
+	StatePaused    = "paused"
+	StateCompleted = "completed"  // a one-shot job that fired
+	StateNotLoaded = "not_loaded" // the scheduler rejected the job, e.g. after a manual edit
+)
+
+// DefaultHistoryLimit is how many executions of a job are shown unless asked otherwise
+const DefaultHistoryLimit = 50
+
+// maxRequestSize bounds the body of API requests
+const maxRequestSize = 1 << 20
+
+// Scheduler is the running scheduler whose jobs are shown and triggered
+type Scheduler interface {
+	Jobs() []types.ScheduledJob
+	Trigger(jobID string) (types.Execution, error)
+	Reload() (types.ReloadSummary, error)
+}
+
+// Store holds the jobs and their execution history
+type Store interface {
+	GetAllJobs() []types.Job
+	UpdateJob(id string, change func(job types.Job, exists bool) (types.Job, error)) error
+	QueryExecutions(filter storage.HistoryFilter) ([]types.Execution, error)
+}
+
+// Server is the web dashboard. It shows the jobs in storage with their state
+// in the scheduler, and saves changes to storage before reloading the
+// scheduler, like the CLI does.
+type Server struct {
+	store     Store
+	scheduler Scheduler
+	validate  func(job types.Job) error
+	redact    *redact.Redactor
+	access    Access
+}
+
+// New creates the dashboard. Created and edited jobs must pass validate
+// before they are saved.
+func New(store Store, scheduler Scheduler, validate func(job types.Job) error, redactor *redact.Redactor, access Access) *Server {
+	return &Server{
+		store:     store,
+		scheduler: scheduler,
+		validate:  validate,
+		redact:    redactor,
+		access:    access,
+	}
+}
+
+// Handler serves the dashboard and its API
+func (s *Server) Handler() http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(assets))
+	mux.HandleFunc("GET /api/jobs", s.listJobs)
+	mux.HandleFunc("POST /api/jobs", s.createJob)
+	mux.HandleFunc("PUT /api/jobs/{id}", s.updateJob)
+	mux.HandleFunc("POST /api/jobs/{id}/trigger", s.triggerJob)
+	mux.HandleFunc("POST /api/jobs/{id}/pause", s.pauseJob)
+	mux.HandleFunc("POST /api/jobs/{id}/resume", s.resumeJob)
+	mux.HandleFunc("GET /api/jobs/{id}/executions", s.listExecutions)
+	mux.HandleFunc("GET /api/schedule", s.checkSchedule)
+	return s.protect(mux)
+}
+
+// Serve runs the dashboard on the address in the background until the
+// returned server is closed. Addresses without a host are bound on
+// 127.0.0.1, and other than loopback addresses only with Access.Remote.
+func (s *Server) Serve(addr string) (*http.Server, error) {
+	addr = ListenAddr(addr)
+	if err := s.access.Check(addr); err != nil {
+		return nil, err
+	}
+	if host, _, _ := net.SplitHostPort(addr); net.ParseIP(host) == nil {
+		s.access.Hosts = append(s.access.Hosts, host)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the dashboard on %s: %v", addr, err)
+	}
+
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Failed to serve dashboard", logging.Err(err))
+		}
+	}()
+	return server, nil
+}
+
+// JobView is a job as listed in the dashboard, with sensitive values redacted
+type JobView struct {
+	ID          string            `json:"id"`
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Schedule    string            `json:"schedule"`            // description of any kind of schedule
+	CronExpr    string            `json:"cron_expr,omitempty"` // empty for one-shot and interval jobs
+	TimeZone    string            `json:"timezone,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	State       string            `json:"state"`
+	PausedUntil *time.Time        `json:"paused_until,omitempty"`
+	NextRun     *time.Time        `json:"next_run,omitempty"`
+	PrevRun     *time.Time        `json:"prev_run,omitempty"`
+}
+
+// JobForm holds the settings the dashboard creates and edits jobs with.
+// Editing keeps the job's other settings, such as retries and assertions.
+type JobForm struct {
+	ID       string            `json:"id"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	CronExpr string            `json:"cron_expr"` // ignored when editing one-shot and interval jobs
+	TimeZone string            `json:"timezone"`
+	Headers  map[string]string `json:"headers"`
+	Body     string            `json:"body"`
+	Tags     []string          `json:"tags"`
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	scheduled := make(map[string]types.ScheduledJob)
+	for _, job := range s.scheduler.Jobs() {
+		scheduled[job.Job.ID] = job
+	}
+
+	jobs := s.store.GetAllJobs()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+	now := time.Now()
+	views := make([]JobView, 0, len(jobs))
+	for _, job := range jobs {
+		shown := s.redact.Job(job)
+		view := JobView{
+			ID:       job.ID,
+			Method:   shown.Method,
+			URL:      shown.URL,
+			Schedule: job.Schedule(),
+			CronExpr: job.CronExpr,
+			TimeZone: job.TimeZone,
+			Headers:  shown.Headers,
+			Body:     shown.Body,
+			Tags:     job.Tags,
+			State:    StateActive,
+		}
+
+		loaded, ok := scheduled[job.ID]
+		switch {
+		case job.CompletedAt != nil:
+			view.State = StateCompleted
+		case job.IsPaused(now):
+			view.State = StatePaused
+			view.PausedUntil = job.PausedUntil
+		case !ok:
+			view.State = StateNotLoaded
+		}
+		if ok {
+			view.NextRun = timeOrNil(loaded.NextRun)
+			view.PrevRun = timeOrNil(loaded.PrevRun)
+		}
+		views = append(views, view)
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) createJob(w http.ResponseWriter, r *http.Request) {
+	var form JobForm
+	if !readJSON(w, r, &form) {
+		return
+	}
+
+	form.ID = strings.TrimSpace(form.ID)
+	saved := s.save(w, form.ID, func(_ types.Job, exists bool) (types.Job, error) {
+		if exists {
+			return types.Job{}, &statusError{http.StatusConflict, fmt.Errorf("job '%s' already exists", form.ID)}
+		}
+		return s.check(form.apply(types.Job{ID: form.ID}))
+	})
+	if saved {
+		writeJSON(w, http.StatusCreated, map[string]string{"id": form.ID})
+	}
+}
+
+func (s *Server) updateJob(w http.ResponseWriter, r *http.Request) {
+	var form JobForm
+	if !readJSON(w, r, &form) {
+		return
+	}
+
+	jobID := r.PathValue("id")
+	if form.ID != "" && form.ID != jobID {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("the job ID cannot be changed from '%s'", jobID))
+		return
+	}
+
+	saved := s.save(w, jobID, func(current types.Job, exists bool) (types.Job, error) {
+		if !exists {
+			return types.Job{}, notFound(jobID)
+		}
+		// The form was filled with redacted values, keep the hidden ones
+		return s.check(s.redact.Restore(current, form.apply(current)))
+	})
+	if saved {
+		writeJSON(w, http.StatusOK, map[string]string{"id": jobID})
+	}
+}
+
+func (s *Server) triggerJob(w http.ResponseWriter, r *http.Request) {
+	exec, err := s.scheduler.Trigger(r.PathValue("id"))
+	if exec.ID == "" && err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	// A failed webhook is still a completed trigger, the execution carries the error
+	writeJSON(w, http.StatusOK, s.redact.Execution(exec))
+}
+
+func (s *Server) pauseJob(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r.PathValue("id"), true)
+}
+
+func (s *Server) resumeJob(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r.PathValue("id"), false)
+}
+
+// setPaused pauses a job until it is resumed, or resumes it
+func (s *Server) setPaused(w http.ResponseWriter, jobID string, paused bool) {
+	saved := s.save(w, jobID, func(job types.Job, exists bool) (types.Job, error) {
+		if !exists {
+			return types.Job{}, notFound(jobID)
+		}
+		job.Paused = paused
+		job.PausedUntil = nil
+		return job, nil
+	})
+	if saved {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) listExecutions(w http.ResponseWriter, r *http.Request) {
+	limit := DefaultHistoryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", value))
+			return
+		}
+		limit = n
+	}
+
+	execs, err := s.store.QueryExecutions(storage.HistoryFilter{JobID: r.PathValue("id"), Limit: limit})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Newest first
+	views := make([]types.Execution, 0, len(execs))
+	for i := len(execs) - 1; i >= 0; i-- {
+		views = append(views, s.redact.Execution(execs[i]))
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+// ScheduleCheck is the outcome of validating a cron expression
+type ScheduleCheck struct {
+	Error string      `json:"error,omitempty"`
+	Next  []time.Time `json:"next,omitempty"` // upcoming run times of a valid schedule
+}
+
+// checkSchedule validates a cron expression in a time zone and lists its next runs
+func (s *Server) checkSchedule(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	job := types.Job{CronExpr: strings.TrimSpace(query.Get("expr")), TimeZone: strings.TrimSpace(query.Get("timezone"))}
+	if job.CronExpr == "" {
+		writeJSON(w, http.StatusOK, ScheduleCheck{Error: "schedule is required"})
+		return
+	}
+
+	sched, err := schedule.Parse(job)
+	if err != nil {
+		writeJSON(w, http.StatusOK, ScheduleCheck{Error: err.Error()})
+		return
+	}
+
+	var check ScheduleCheck
+	next := time.Now()
+	for range 5 {
+		if next = sched.Next(next); next.IsZero() {
+			break
+		}
+		check.Next = append(check.Next, next)
+	}
+	writeJSON(w, http.StatusOK, check)
+}
+
+// statusError is an error answered with its own status rather than 500
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func notFound(jobID string) error {
+	return &statusError{http.StatusNotFound, fmt.Errorf("job '%s' not found", jobID)}
+}
+
+// save applies change to the stored job and has the scheduler pick up the
+// result. The job is read and written under the storage lock, so a change
+// made meanwhile by the CLI or the scheduler is never overwritten. Failures
+// are answered; it reports whether the job was saved.
+func (s *Server) save(w http.ResponseWriter, jobID string, change func(job types.Job, exists bool) (types.Job, error)) bool {
+	err := s.store.UpdateJob(jobID, change)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		writeError(w, statusErr.status, statusErr.err)
+		return false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to save job '%s': %v", jobID, err))
+		return false
+	}
+	s.reload()
+	return true
+}
+
+// check returns the job if it is valid to save
+func (s *Server) check(job types.Job) (types.Job, error) {
+	if err := s.validate(job); err != nil {
+		return types.Job{}, &statusError{http.StatusBadRequest, s.redact.Error(err)}
+	}
+	return job, nil
+}
+
+// reload applies saved changes to the scheduler. A failure leaves the change
+// saved, to be picked up by the next reload.
+func (s *Server) reload() {
+	if _, err := s.scheduler.Reload(); err != nil {
+		slog.Warn("Failed to reload jobs after a dashboard change", logging.Err(err))
+	}
+}
+
+// apply returns the job with the form's settings
+func (f JobForm) apply(job types.Job) types.Job {
+	job.Method = strings.ToUpper(strings.TrimSpace(f.Method))
+	if job.Method == "" {
+		job.Method = http.MethodGet
+	}
+	job.URL = strings.TrimSpace(f.URL)
+	job.TimeZone = strings.TrimSpace(f.TimeZone)
+	if job.ScheduleKind() == types.ScheduleCron {
+		job.CronExpr = strings.TrimSpace(f.CronExpr)
+	}
+	job.Headers = f.Headers
+	if len(job.Headers) == 0 {
+		job.Headers = nil
+	}
+	job.Body = f.Body
+
+	job.Tags = nil
+	for _, tag := range f.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			job.Tags = append(job.Tags, tag)
+		}
+	}
+	return job
+}
+
+// readJSON decodes the request body, answering 400 if it is invalid
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to encode dashboard response", logging.Err(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
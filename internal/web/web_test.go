@@ -0,0 +1,91 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tempo/internal/storage"
+	"tempo/internal/types"
+	"testing"
+)
+
+func TestJobChanges(t *testing.T) {
+	dir := t.TempDir()
+	dashboard, err := storage.NewJSONStorage(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := storage.NewJSONStorage(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.AddJob(types.Job{ID: "a", Method: "GET", URL: "https://example.com/old", CronExpr: "0 * * * * *"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dashboard.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(job types.Job) error {
+		if job.URL == "" {
+			return errors.New("url is required")
+		}
+		return nil
+	}
+	handler := New(dashboard, fakeScheduler{}, validate, nil, Access{Token: testToken}).Handler()
+
+	// The CLI changes the job after the dashboard loaded it
+	if err := cli.AddJob(types.Job{ID: "a", Method: "GET", URL: "https://example.com/new", CronExpr: "0 * * * * *"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "pause", method: "POST", path: "/api/jobs/a/pause", want: http.StatusNoContent},
+		{name: "pause missing job", method: "POST", path: "/api/jobs/missing/pause", want: http.StatusNotFound},
+		{name: "resume missing job", method: "POST", path: "/api/jobs/missing/resume", want: http.StatusNotFound},
+		{name: "update missing job", method: "PUT", path: "/api/jobs/missing", body: `{"url": "https://example.com/", "cron_expr": "0 * * * * *"}`, want: http.StatusNotFound},
+		{name: "update changing the ID", method: "PUT", path: "/api/jobs/a", body: `{"id": "b", "url": "https://example.com/", "cron_expr": "0 * * * * *"}`, want: http.StatusBadRequest},
+		{name: "invalid update", method: "PUT", path: "/api/jobs/a", body: `{"url": "", "cron_expr": "0 * * * * *"}`, want: http.StatusBadRequest},
+		{name: "create existing job", method: "POST", path: "/api/jobs", body: `{"id": "a", "url": "https://example.com/", "cron_expr": "0 * * * * *"}`, want: http.StatusConflict},
+		{name: "create", method: "POST", path: "/api/jobs", body: `{"id": "b", "url": "https://example.com/", "cron_expr": "0 * * * * *"}`, want: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Host = "127.0.0.1:8080"
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, rec.Code, strings.TrimSpace(rec.Body.String()), tt.want)
+			}
+		})
+	}
+
+	if err := cli.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	job, _ := cli.GetJob("a")
+	if !job.Paused {
+		t.Error("the job was not paused")
+	}
+	if job.URL != "https://example.com/new" {
+		t.Errorf("URL = %q, the change made by the CLI was overwritten", job.URL)
+	}
+	if _, ok := cli.GetJob("missing"); ok {
+		t.Error("a request for a missing job created it")
+	}
+	if _, ok := cli.GetJob("b"); !ok {
+		t.Error("the job was not created")
+	}
+}